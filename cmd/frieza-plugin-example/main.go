@@ -0,0 +1,147 @@
+// This program is a reference frieza plugin, you can use it as a starting
+// point to write your own. It simulates widgets stored in widget groups, kept
+// in memory for the lifetime of the process.
+// Check docs/plugins.md for more details.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+	"github.com/outscale/frieza/internal/providers/plugin"
+)
+
+const (
+	typeWidget      = "widget"
+	typeWidgetGroup = "widget_group"
+)
+
+type widget struct {
+	object Object
+	group  string
+}
+
+type store struct {
+	groups  []Object
+	widgets []widget
+}
+
+func newStore() *store {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &store{}
+	for i := range 2 {
+		group := Object{Id: fmt.Sprintf("group-%d", i), Name: fmt.Sprintf("example-group-%d", i)}
+		group.SetCreatedAt(createdAt)
+		store.groups = append(store.groups, group)
+		for j := range 3 {
+			object := Object{
+				Id:   fmt.Sprintf("widget-%d-%d", i, j),
+				Name: fmt.Sprintf("example-widget-%d-%d", i, j),
+				Tags: map[string]string{"group": group.Name},
+			}
+			object.SetCreatedAt(createdAt)
+			store.widgets = append(store.widgets, widget{object: object, group: group.Id})
+		}
+	}
+	return store
+}
+
+func (store *store) handle(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case plugin.MethodInit:
+		var init plugin.InitParams
+		if err := json.Unmarshal(params, &init); err != nil {
+			return nil, err
+		}
+		if init.ProtocolVersion != plugin.ProtocolVersion {
+			return nil, fmt.Errorf("unsupported protocol version %d", init.ProtocolVersion)
+		}
+		return plugin.InitReply{ProtocolVersion: plugin.ProtocolVersion}, nil
+	case plugin.MethodTypes:
+		return plugin.TypesReply{
+			Types:        []ObjectType{typeWidget, typeWidgetGroup},
+			Dependencies: map[ObjectType][]ObjectType{typeWidgetGroup: {typeWidget}},
+			TaggedTypes:  []ObjectType{typeWidget},
+		}, nil
+	case plugin.MethodAuthTest:
+		return struct{}{}, nil
+	case plugin.MethodRead:
+		var read plugin.ReadParams
+		if err := json.Unmarshal(params, &read); err != nil {
+			return nil, err
+		}
+		return plugin.ReadReply{Objects: store.read(read.Type)}, nil
+	case plugin.MethodDelete:
+		var deletion plugin.DeleteParams
+		if err := json.Unmarshal(params, &deletion); err != nil {
+			return nil, err
+		}
+		var results []plugin.DeleteObjectResult
+		for _, object := range deletion.Objects {
+			results = append(results, store.delete(deletion.Type, object.Id))
+		}
+		return plugin.DeleteReply{Results: results}, nil
+	case plugin.MethodStringObject:
+		var stringObject plugin.StringObjectParams
+		if err := json.Unmarshal(params, &stringObject); err != nil {
+			return nil, err
+		}
+		return plugin.StringObjectReply{String: stringObject.Object.String()}, nil
+	default:
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+}
+
+func (store *store) read(typeName ObjectType) []Object {
+	objects := []Object{}
+	switch typeName {
+	case typeWidget:
+		for _, widget := range store.widgets {
+			objects = append(objects, widget.object)
+		}
+	case typeWidgetGroup:
+		objects = append(objects, store.groups...)
+	}
+	return objects
+}
+
+func (store *store) delete(typeName ObjectType, id string) plugin.DeleteObjectResult {
+	result := plugin.DeleteObjectResult{Id: id}
+	switch typeName {
+	case typeWidget:
+		index := slices.IndexFunc(store.widgets, func(widget widget) bool { return widget.object.Id == id })
+		if index < 0 {
+			result.Kind, result.Error = DeleteErrorNotFound, "widget not found"
+			return result
+		}
+		store.widgets = slices.Delete(store.widgets, index, index+1)
+	case typeWidgetGroup:
+		index := slices.IndexFunc(store.groups, func(group Object) bool { return group.Id == id })
+		if index < 0 {
+			result.Kind, result.Error = DeleteErrorNotFound, "widget group not found"
+			return result
+		}
+		if slices.ContainsFunc(store.widgets, func(widget widget) bool { return widget.group == id }) {
+			result.Kind, result.Error = DeleteErrorDependency, "widget group is not empty"
+			return result
+		}
+		store.groups = slices.Delete(store.groups, index, index+1)
+	default:
+		result.Kind, result.Error = DeleteErrorForbidden, "unknown type "+typeName
+	}
+	return result
+}
+
+func main() {
+	log.SetFlags(0)
+	// Standard output is reserved to the protocol, logs go to standard error.
+	log.SetOutput(os.Stderr)
+	if err := plugin.Serve(os.Stdin, os.Stdout, newStore().handle); err != nil {
+		log.Fatal(err)
+	}
+}
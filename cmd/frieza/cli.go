@@ -1,9 +1,14 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"io"
 	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	. "github.com/outscale/frieza/internal/common"
 	"github.com/teris-io/cli"
@@ -37,6 +42,264 @@ func cliJson() cli.Option {
 		WithType(cli.TypeBool)
 }
 
+func cliTag() cli.Option {
+	return cli.NewOption("tag", "Select only resources having all these tags (key=value separated by ',')").
+		WithType(cli.TypeString)
+}
+
+func cliExcludeTag() cli.Option {
+	return cli.NewOption("exclude-tag", "Ignore resources having any of these tags (key=value separated by ',')").
+		WithType(cli.TypeString)
+}
+
+func cliMatch() cli.Option {
+	return cli.NewOption("match", "Select only resources whose id or name match one of these patterns, of their types only (type:[id|name]=regex or type:[id|name]~glob, repeat the option for several patterns)").
+		WithType(cli.TypeString)
+}
+
+func cliExcludeMatch() cli.Option {
+	return cli.NewOption("exclude-match", "Ignore resources whose id or name match any of these patterns (type:[id|name]=regex or type:[id|name]~glob, repeat the option for several patterns)").
+		WithType(cli.TypeString)
+}
+
+// repeatableOptions can be given several times. The cli parser only keeps
+// the last value of an option, so values are joined with optionSeparator
+// before parsing.
+var repeatableOptions = []string{"match", "exclude-match"}
+
+const optionSeparator = "\n"
+
+// joinRepeatedOptions merges the values of repeated options of args into
+// their first occurrence.
+func joinRepeatedOptions(args []string) []string {
+	joined := make([]string, 0, len(args))
+	first := make(map[string]int)
+	for i, arg := range args {
+		if arg == "--" {
+			return append(joined, args[i:]...)
+		}
+		key, value, found := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if found && strings.HasPrefix(arg, "--") && slices.Contains(repeatableOptions, key) {
+			if index, ok := first[key]; ok {
+				joined[index] += optionSeparator + value
+				continue
+			}
+			first[key] = len(joined)
+		}
+		joined = append(joined, arg)
+	}
+	return joined
+}
+
+// splitOption returns the values of a repeatable option.
+func splitOption(options map[string]string, name string) []string {
+	if len(options[name]) == 0 {
+		return nil
+	}
+	return strings.Split(options[name], optionSeparator)
+}
+
+// parseTagOptions reads --tag and --exclude-tag options.
+func parseTagOptions(options map[string]string) (map[string]string, map[string]string) {
+	var tags, excludeTags map[string]string
+	var err error
+	if len(options["tag"]) > 0 {
+		if tags, err = ParseTags(options["tag"]); err != nil {
+			cliFatalf(options["json"] == "true", "Invalid --tag option: %s", err.Error())
+		}
+	}
+	if len(options["exclude-tag"]) > 0 {
+		if excludeTags, err = ParseTags(options["exclude-tag"]); err != nil {
+			cliFatalf(options["json"] == "true", "Invalid --exclude-tag option: %s", err.Error())
+		}
+	}
+	return tags, excludeTags
+}
+
+// parsePatternOptions reads --match and --exclude-match options.
+func parsePatternOptions(options map[string]string) ([]ObjectPattern, []ObjectPattern) {
+	var patterns, excludePatterns []ObjectPattern
+	var err error
+	if len(options["match"]) > 0 {
+		if patterns, err = ParseObjectPatterns(splitOption(options, "match")); err != nil {
+			cliFatalf(options["json"] == "true", "Invalid --match option: %s", err.Error())
+		}
+	}
+	if len(options["exclude-match"]) > 0 {
+		if excludePatterns, err = ParseObjectPatterns(splitOption(options, "exclude-match")); err != nil {
+			cliFatalf(options["json"] == "true", "Invalid --exclude-match option: %s", err.Error())
+		}
+	}
+	return patterns, excludePatterns
+}
+
+// parseObjectSelectors builds a filter from tag and pattern options, nil when
+// none is set. With --match, only the types of its patterns are selected.
+func parseObjectSelectors(options map[string]string) *ResourceFilterEnvelope {
+	tags, excludeTags := parseTagOptions(options)
+	patterns, excludePatterns := parsePatternOptions(options)
+	if len(tags) == 0 && len(excludeTags) == 0 && len(patterns) == 0 && len(excludePatterns) == 0 {
+		return nil
+	}
+	filter := NewResourceFilter()
+	if len(patterns) > 0 {
+		filter = NewResourceFilterOnly(PatternTypes(patterns))
+	}
+	return filter.
+		WithTags(tags, excludeTags).
+		WithPatterns(patterns, excludePatterns)
+}
+
+// parseResourceFilter builds the filter from resource type, tag and pattern
+// options, nil when none is set.
+func parseResourceFilter(options map[string]string) *ResourceFilterEnvelope {
+	var filter *ResourceFilterEnvelope
+	if len(options["only-resource-types"]) > 0 && len(options["exclude-resource-types"]) > 0 {
+		cliFatalf(true, "Cannot use --only-resource-types option with --exclude-resource-types")
+	}
+	if len(options["only-resource-types"]) > 0 {
+		filter = NewResourceFilterOnly(strings.Split(options["only-resource-types"], ","))
+	}
+	if len(options["exclude-resource-types"]) > 0 {
+		filter = NewResourceFilterExclude(strings.Split(options["exclude-resource-types"], ","))
+	}
+	return combineFilters(filter, parseObjectSelectors(options))
+}
+
+// parseAgeOption reads a minimum age option like --older-than, nil when not
+// set.
+func parseAgeOption(options map[string]string, name string) *ResourceFilterEnvelope {
+	if len(options[name]) == 0 {
+		return nil
+	}
+	minAge, err := time.ParseDuration(options[name])
+	if err != nil || minAge <= 0 {
+		cliFatalf(options["json"] == "true", "Invalid --%s option: %s", name, options[name])
+	}
+	return NewResourceFilterAge(minAge)
+}
+
+// combineFilters returns a filter selecting what both filters select, any of
+// them can be nil.
+func combineFilters(filter *ResourceFilterEnvelope, other *ResourceFilterEnvelope) *ResourceFilterEnvelope {
+	if other == nil {
+		return filter
+	}
+	if filter == nil {
+		filter = NewResourceFilter()
+	}
+	return filter.With(other)
+}
+
+// warnUntagged reports types skipped because tag filters cannot apply.
+func warnUntagged(targets []InventoryTarget, jsonOutput bool) {
+	if jsonOutput {
+		return
+	}
+	for _, target := range targets {
+		if len(target.Untagged) == 0 {
+			continue
+		}
+		log.Printf(
+			"Warning: %s does not support tags on %s, these types are skipped\n",
+			target.Name,
+			strings.Join(target.Untagged, ", "),
+		)
+	}
+}
+
+func cliParallelism() cli.Option {
+	return cli.NewOption("parallelism", "maximum number of resource types read at the same time").
+		WithType(cli.TypeInt)
+}
+
+func parseParallelism(options map[string]string) int {
+	if len(options["parallelism"]) == 0 {
+		return 0
+	}
+	parallelism, err := strconv.Atoi(options["parallelism"])
+	if err != nil || parallelism < 1 {
+		cliFatalf(options["json"] == "true", "Invalid parallelism: %s", options["parallelism"])
+	}
+	return parallelism
+}
+
+func cliDestroyParallelism() cli.Option {
+	return cli.NewOption("destroy-parallelism", "maximum number of profiles deleted from at the same time").
+		WithType(cli.TypeInt)
+}
+
+func parseDestroyParallelism(options map[string]string) int {
+	if len(options["destroy-parallelism"]) == 0 {
+		return 0
+	}
+	parallelism, err := strconv.Atoi(options["destroy-parallelism"])
+	if err != nil || parallelism < 1 {
+		cliFatalf(options["json"] == "true", "Invalid destroy parallelism: %s", options["destroy-parallelism"])
+	}
+	return parallelism
+}
+
+// newInventory prefers the --parallelism option over the configured one.
+func newInventory(parallelism int, config *Config) *Inventory {
+	return NewInventory(cmp.Or(parallelism, config.Parallelism))
+}
+
+func newProfileTarget(profile *Profile, provider *Provider, filters *ResourceFilterEnvelope) InventoryTarget {
+	name := fmt.Sprintf("%s (%s)", profile.Name, (*provider).Name())
+	return NewInventoryTarget(name, provider, filters)
+}
+
+// parseTimeout reads the --timeout option, 10 minutes when not set. It is
+// parsed before any confirmation, so that a typo does not stop a confirmed
+// deletion.
+func parseTimeout(options map[string]string, jsonOutput bool) time.Duration {
+	timeout, err := time.ParseDuration(cmp.Or(options["timeout"], "10m"))
+	if err != nil {
+		cliFatalf(jsonOutput, "Could not parse timeout: %v", err)
+	}
+	return timeout
+}
+
+// checkPatternTypes refuses patterns on types that no provider of targets
+// has, as they would silently select nothing.
+func checkPatternTypes(targets []InventoryTarget, jsonOutput bool) {
+	var types, patternTypes []ObjectType
+	for _, target := range targets {
+		types = append(types, (*target.Provider).Types()...)
+		if target.Filters != nil {
+			patternTypes = append(patternTypes, target.Filters.PatternTypes()...)
+		}
+	}
+	for _, typeName := range patternTypes {
+		if !slices.Contains(types, typeName) {
+			slices.Sort(types)
+			cliFatalf(jsonOutput, "Invalid pattern type %s, known types are: %s", typeName, strings.Join(slices.Compact(types), ", "))
+		}
+	}
+}
+
+// requireSignedPlan refuses direct deletions when plans must be signed:
+// resources can then only be deleted by applying a signed plan.
+func requireSignedPlan(config *Config, plan bool, jsonOutput bool) {
+	if config.RequiredSignatures > 0 && !plan {
+		cliFatalf(jsonOutput, "%d plan signatures are required: save a plan with --out, sign it with plan sign and delete its resources with apply", config.RequiredSignatures)
+	}
+}
+
+// skipUnusedTags lets providers skip reading tags of target when neither its
+// filters nor the protect rules of profile use them.
+func skipUnusedTags(target InventoryTarget, config *Config, profile *Profile) InventoryTarget {
+	if target.Filters != nil && target.Filters.HasTagFilters() {
+		return target
+	}
+	if slices.ContainsFunc(config.ProtectedRules(profile), func(rule ProtectRule) bool { return len(rule.Tags) > 0 }) {
+		return target
+	}
+	target.SkipTags = true
+	return target
+}
+
 func cliFatalf(json bool, format string, v ...any) {
 	msg := fmt.Sprintf(format, v...)
 	if json {
@@ -52,6 +315,11 @@ func cliRoot() cli.App {
 		WithCommand(cliSnapshot()).
 		WithCommand(cliClean()).
 		WithCommand(cliNuke()).
+		WithCommand(cliApply()).
+		WithCommand(cliResume()).
+		WithCommand(cliPlan()).
+		WithCommand(cliHistory()).
+		WithCommand(cliProtect()).
 		WithCommand(cliProvider()).
 		WithCommand(cliConfig()).
 		WithCommand(cliVersion())
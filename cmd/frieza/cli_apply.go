@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+	"github.com/teris-io/cli"
+)
+
+func cliApply() cli.Command {
+	return cli.NewCommand("apply", "delete the resources of a plan saved by clean or nuke with --out").
+		WithArg(cli.NewArg("plan", "plan file")).
+		WithOption(cli.NewOption("ttl", "Refuse plans older than this duration (default: 1h)").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("replan", "Apply expired plans and plans whose resources changed, skipping resources which no longer exist or are now protected").WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("timeout", "Exit with error after a specific duration (ex: 30s, 5m, 1.5h)").WithType(cli.TypeString)).
+		WithOption(cliJson()).
+		WithOption(cliParallelism()).
+		WithOption(cliDestroyParallelism()).
+		WithOption(cli.NewOption("auto-approve", "Approve resource deletion without confirmation").WithType(cli.TypeBool)).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			autoApprove := options["auto-approve"] == "true"
+			jsonOutput := options["json"] == "true"
+			timeout := parseTimeout(options, jsonOutput)
+			ttl := DefaultPlanTTL
+			if len(options["ttl"]) > 0 {
+				var err error
+				if ttl, err = time.ParseDuration(options["ttl"]); err != nil || ttl <= 0 {
+					cliFatalf(jsonOutput, "Invalid --ttl option: %s", options["ttl"])
+				}
+			}
+
+			apply(options["config"], args[0], ttl, options["replan"] == "true", autoApprove, jsonOutput, timeout, parseParallelism(options), parseDestroyParallelism(options))
+			return 0
+		})
+}
+
+func apply(customConfigPath string, planPath string, ttl time.Duration, replan bool, autoApprove bool, jsonOutput bool, timeout time.Duration, parallelism int, destroyParallelism int) {
+	if jsonOutput && !autoApprove {
+		cliFatalf(true, "Cannot use --json option without --auto-approve")
+	}
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load configuration: %s", err.Error())
+	}
+	plan, err := PlanLoad(planPath)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load plan %s: %s", planPath, err.Error())
+	}
+	signers, err := plan.Signers(config)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot apply plan %s: %s", planPath, err.Error())
+	}
+	if len(signers) < config.RequiredSignatures {
+		cliFatalf(jsonOutput, "Plan %s is signed by %d trusted keys, %d are required: sign it with plan sign", planPath, len(signers), config.RequiredSignatures)
+	}
+	if len(signers) > 0 && !jsonOutput {
+		log.Printf("Plan signed by %s\n", strings.Join(signers, ", "))
+	}
+	expired := plan.Age() > ttl
+	if expired && !replan {
+		cliFatalf(jsonOutput, "Plan %s was made %s ago, more than %s: plan again or use --replan", planPath, plan.Age().Round(time.Second), ttl)
+	}
+
+	ctx := context.Background()
+
+	destroyer, mismatches, err := plan.destroyer(ctx, config, newInventory(parallelism, config))
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot apply plan %s: %s", planPath, err.Error())
+	}
+	destroyer.appliedPlan = plan
+	if len(mismatches) > 0 && !replan {
+		var reasons []string
+		for _, mismatch := range mismatches {
+			reasons = append(reasons, mismatch.String())
+		}
+		cliFatalf(jsonOutput, "Plan %s no longer matches resources, plan again or use --replan: %s", planPath, strings.Join(reasons, "; "))
+	}
+	if len(mismatches) > 0 {
+		if !jsonOutput {
+			log.Printf("Plan updated, %d planned resources are skipped:\n", len(mismatches))
+			for _, mismatch := range mismatches {
+				log.Printf("  - %s\n", mismatch)
+			}
+		}
+	}
+
+	destroyer.print(jsonOutput)
+	objectsCount := 0
+	for _, target := range destroyer.Targets {
+		objectsCount += ObjectsCount(target.Objects)
+	}
+	if objectsCount == 0 {
+		return
+	}
+	if jsonOutput {
+		disableLogs()
+	}
+	message := "Do you really want to delete resources of this plan?\n" +
+		"  Frieza will delete all resources shown above."
+	if !confirmAction(&message, autoApprove) {
+		log.Fatal("Apply canceled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := destroyer.run(ctx, destroyParallelism)
+	report.print(jsonOutput)
+	if code := report.exitCode(); code != 0 {
+		cancel()
+		os.Exit(code)
+	}
+}
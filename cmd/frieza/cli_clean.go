@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"slices"
 	"time"
 
@@ -13,28 +14,37 @@ import (
 func cliClean() cli.Command {
 	return cli.NewCommand("clean", "delete created resources since a specific snapshot").
 		WithOption(cli.NewOption("plan", "Only show what resource would be deleted").WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("out", "Save the plan in this file to delete its resources later with apply (implies --plan)").WithType(cli.TypeString)).
 		WithOption(cli.NewOption("timeout", "Exit with error after a specific duration (ex: 30s, 5m, 1.5h)").WithType(cli.TypeString)).
 		WithOption(cliJson()).
+		WithOption(cliTag()).
+		WithOption(cliExcludeTag()).
+		WithOption(cliMatch()).
+		WithOption(cliExcludeMatch()).
+		WithOption(cli.NewOption("min-age", "Remove only resources created before this duration (ex: 30m, 72h). Resources without creation date are ignored.").WithType(cli.TypeString)).
+		WithOption(cliParallelism()).
+		WithOption(cliDestroyParallelism()).
 		WithOption(cli.NewOption("auto-approve", "Approve resource deletion without confirmation").WithType(cli.TypeBool)).
 		WithArg(cli.NewArg("snapshot_name", "snapshot")).
 		WithOption(cliConfigPath()).
 		WithOption(cliDebug()).
 		WithAction(func(args []string, options map[string]string) int {
 			setupDebug(options)
-			plan := options["plan"] == "true"
+			planPath := options["out"]
+			plan := options["plan"] == "true" || len(planPath) > 0
 			autoApprove := options["auto-approve"] == "true"
 			jsonOutput := options["json"] == "true"
-			timeout := "10m"
-			if len(options["timeout"]) > 0 {
-				timeout = options["timeout"]
-			}
+			timeout := parseTimeout(options, jsonOutput)
 
-			clean(options["config"], &args[0], plan, autoApprove, jsonOutput, timeout)
+			parallelism := parseParallelism(options)
+			selectors := combineFilters(parseObjectSelectors(options), parseAgeOption(options, "min-age"))
+
+			clean(options["config"], &args[0], plan, planPath, autoApprove, jsonOutput, timeout, parallelism, parseDestroyParallelism(options), selectors)
 			return 0
 		})
 }
 
-func clean(customConfigPath string, snapshotName *string, plan bool, autoApprove bool, jsonOutput bool, timeout string) {
+func clean(customConfigPath string, snapshotName *string, plan bool, planPath string, autoApprove bool, jsonOutput bool, timeout time.Duration, parallelism int, destroyParallelism int, selectors *ResourceFilterEnvelope) {
 	var configPath *string
 	if jsonOutput && !autoApprove {
 		cliFatalf(true, "Cannot use --json option without --auto-approve")
@@ -46,16 +56,21 @@ func clean(customConfigPath string, snapshotName *string, plan bool, autoApprove
 	if err != nil {
 		cliFatalf(jsonOutput, "Cannot load configuration: %s", err.Error())
 	}
-	snapshot, err := SnapshotLoad(*snapshotName, config)
+	requireSignedPlan(config, plan, jsonOutput)
+	// Only identifiers of the snapshot are needed, which keeps large
+	// snapshots out of memory.
+	snapshot, err := SnapshotIndexLoad(*snapshotName, config)
 	if err != nil {
 		cliFatalf(jsonOutput, "Error load snapshot %s: %s", *snapshotName, err.Error())
 	}
 
-	ctx := context.Background()
+	filters := combineFilters(snapshot.Filters, selectors)
 
-	destroyer := NewDestroyer()
-	objectsCount := 0
+	ctx := context.Background()
 
+	var targets []InventoryTarget
+	var targetProfiles []*Profile
+	var known []ObjectIdSets
 	for _, data := range snapshot.Data {
 		profile, err := config.GetProfile(data.Profile)
 		if err != nil {
@@ -74,19 +89,33 @@ func clean(customConfigPath string, snapshotName *string, plan bool, autoApprove
 			continue
 		}
 
-		provider := &providers[idx]
-		currentObjects, err := ReadObjects(ctx, provider, snapshot.Filters)
-		if err != nil {
-			log.Fatalf("Error reading objects: %v", err)
+		target := newProfileTarget(profile, &providers[idx], filters)
+		// Saved plans keep the tags of objects.
+		if len(planPath) == 0 {
+			target = skipUnusedTags(target, config, profile)
 		}
+		targets = append(targets, target)
+		targetProfiles = append(targetProfiles, profile)
+		known = append(known, data.Ids)
+	}
+	checkPatternTypes(targets, jsonOutput)
+	warnUntagged(targets, jsonOutput)
+	created, err := newInventory(parallelism, config).CollectNew(ctx, targets, known)
+	if err != nil {
+		log.Fatalf("Error reading objects: %v", err)
+	}
 
-		diff := NewDiff()
-		diff.Build(&data.Objects, &currentObjects)
-		objectsCount += ObjectsCount(&diff.Created)
-		destroyer.add(profile, provider, &diff.Created)
+	destroyer := NewDestroyer(config, "clean")
+	objectsCount := 0
+	for i, target := range targets {
+		destroyer.add(targetProfiles[i], target.Provider, &created[i].Objects, created[i].Skipped)
+		objectsCount += ObjectsCount(&created[i].Objects)
 	}
 
 	destroyer.print(jsonOutput)
+	if len(planPath) > 0 {
+		destroyer.savePlan(planPath, "clean", *snapshotName, filters, jsonOutput)
+	}
 	if plan || objectsCount == 0 {
 		return
 	}
@@ -99,12 +128,13 @@ func clean(customConfigPath string, snapshotName *string, plan bool, autoApprove
 		log.Fatal("Clean canceled")
 	}
 
-	tout, err := time.ParseDuration(timeout)
-	if err != nil {
-		log.Fatal("Could not parse timeout: %w", err)
-	}
-	ctx, cancel := context.WithTimeout(ctx, tout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	destroyer.run(ctx)
+	report := destroyer.run(ctx, destroyParallelism)
+	report.print(jsonOutput)
+	if code := report.exitCode(); code != 0 {
+		cancel()
+		os.Exit(code)
+	}
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"strconv"
 
 	. "github.com/outscale/frieza/internal/common"
 	"github.com/teris-io/cli"
@@ -58,6 +59,14 @@ func cliConfigRm() cli.Command {
 
 func configDescribe() {
 	log.Println("snapshot_folder_path: specify a folder path where snapshots are located")
+	log.Println("snapshot_store: where snapshots are stored, \"local\" (default, in snapshot_folder_path) or s3://bucket/prefix?profile=name to use the s3 or outscale_oos provider of a profile")
+	log.Println("snapshot_encoding: how new snapshots are written, \"json\" (default) or \"compact\" for gzip compressed JSON lines, suited to large inventories")
+	log.Printf("parallelism: maximum number of resource types read at the same time (default: %d)\n", DefaultParallelism)
+	log.Printf("destroy_parallelism: maximum number of profiles deleted from at the same time (default: %d)\n", DefaultDestroyParallelism)
+	log.Printf("stall_rounds: number of deletion rounds without progress after which frieza gives up (default: %d)\n", DefaultStallRounds)
+	log.Println("journal_path: file where deletions are recorded (default: ~/.frieza/journal.jsonl), shown by history")
+	log.Println("run_folder_path: folder where interrupted runs are kept to be resumed (default: ~/.frieza/runs)")
+	log.Println("required_signatures: number of trusted keys which must sign a plan before apply (default: 0), keys are trusted with plan trust")
 }
 
 func configLs(customConfigPath string) {
@@ -75,6 +84,49 @@ func configLs(customConfigPath string) {
 	} else {
 		log.Println("snapshot_folder_path:", config.SnapshotFolderPath)
 	}
+	if len(config.SnapshotStore) == 0 {
+		log.Println("snapshot_store: (unset)")
+	} else {
+		log.Println("snapshot_store:", config.SnapshotStore)
+	}
+	if len(config.SnapshotEncoding) == 0 {
+		log.Println("snapshot_encoding: (unset)")
+	} else {
+		log.Println("snapshot_encoding:", config.SnapshotEncoding)
+	}
+	if config.Parallelism == 0 {
+		log.Println("parallelism: (unset)")
+	} else {
+		log.Println("parallelism:", config.Parallelism)
+	}
+	if config.DestroyParallelism == 0 {
+		log.Println("destroy_parallelism: (unset)")
+	} else {
+		log.Println("destroy_parallelism:", config.DestroyParallelism)
+	}
+	if config.StallRounds == 0 {
+		log.Println("stall_rounds: (unset)")
+	} else {
+		log.Println("stall_rounds:", config.StallRounds)
+	}
+	if len(config.JournalPath) == 0 {
+		log.Println("journal_path: (unset)")
+	} else {
+		log.Println("journal_path:", config.JournalPath)
+	}
+	if len(config.RunFolderPath) == 0 {
+		log.Println("run_folder_path: (unset)")
+	} else {
+		log.Println("run_folder_path:", config.RunFolderPath)
+	}
+	if config.RequiredSignatures == 0 {
+		log.Println("required_signatures: (unset)")
+	} else {
+		log.Println("required_signatures:", config.RequiredSignatures)
+	}
+	for _, key := range config.TrustedKeys {
+		log.Printf("trusted key %s: %s\n", key.Name, key.PublicKey)
+	}
 }
 
 func configSet(customConfigPath string, optionName *string, optionValue *string) {
@@ -89,6 +141,47 @@ func configSet(customConfigPath string, optionName *string, optionValue *string)
 	switch *optionName {
 	case "snapshot_folder_path":
 		config.SnapshotFolderPath = *optionValue
+	case "snapshot_store":
+		config.SnapshotStore = *optionValue
+		if _, err := OpenSnapshotStore(config); err != nil {
+			log.Fatalf("Invalid snapshot store: %s", err.Error())
+		}
+	case "snapshot_encoding":
+		if *optionValue != SnapshotEncodingJson && *optionValue != SnapshotEncodingCompact {
+			log.Fatalf("snapshot_encoding must be %s or %s", SnapshotEncodingJson, SnapshotEncodingCompact)
+		}
+		config.SnapshotEncoding = *optionValue
+	case "parallelism":
+		parallelism, err := strconv.Atoi(*optionValue)
+		if err != nil || parallelism < 1 {
+			log.Fatalf("parallelism must be a positive number")
+		}
+		config.Parallelism = parallelism
+	case "destroy_parallelism":
+		destroyParallelism, err := strconv.Atoi(*optionValue)
+		if err != nil || destroyParallelism < 1 {
+			log.Fatalf("destroy_parallelism must be a positive number")
+		}
+		config.DestroyParallelism = destroyParallelism
+	case "stall_rounds":
+		stallRounds, err := strconv.Atoi(*optionValue)
+		if err != nil || stallRounds < 1 {
+			log.Fatalf("stall_rounds must be a positive number")
+		}
+		config.StallRounds = stallRounds
+	case "journal_path":
+		config.JournalPath = *optionValue
+	case "run_folder_path":
+		config.RunFolderPath = *optionValue
+	case "required_signatures":
+		requiredSignatures, err := strconv.Atoi(*optionValue)
+		if err != nil || requiredSignatures < 0 {
+			log.Fatalf("required_signatures must be 0 or more")
+		}
+		if requiredSignatures > len(config.TrustedKeys) {
+			log.Fatalf("Only %d keys are trusted, add keys with plan trust first", len(config.TrustedKeys))
+		}
+		config.RequiredSignatures = requiredSignatures
 	default:
 		log.Fatalf("Unknow option name")
 	}
@@ -109,6 +202,22 @@ func configRm(customConfigPath string, optionName *string) {
 	switch *optionName {
 	case "snapshot_folder_path":
 		config.SnapshotFolderPath = ""
+	case "snapshot_store":
+		config.SnapshotStore = ""
+	case "snapshot_encoding":
+		config.SnapshotEncoding = ""
+	case "parallelism":
+		config.Parallelism = 0
+	case "destroy_parallelism":
+		config.DestroyParallelism = 0
+	case "stall_rounds":
+		config.StallRounds = 0
+	case "journal_path":
+		config.JournalPath = ""
+	case "run_folder_path":
+		config.RunFolderPath = ""
+	case "required_signatures":
+		config.RequiredSignatures = 0
 	default:
 		log.Fatalf("Unknow option name")
 	}
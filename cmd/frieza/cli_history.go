@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+	"github.com/teris-io/cli"
+)
+
+func cliHistory() cli.Command {
+	return cli.NewCommand("history", "show deletions recorded in the journal").
+		WithOption(cli.NewOption("since", "Only show deletions since this date (RFC 3339 or 2006-01-02) or duration (ex: 24h)").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("until", "Only show deletions before this date (RFC 3339 or 2006-01-02) or duration (ex: 24h)").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("profile", "Only show deletions in this profile").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("type", "Only show deletions of this resource type").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("json", "output in json format").WithType(cli.TypeBool)).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			jsonOutput := options["json"] == "true"
+			query := JournalQuery{
+				Since:   parseTimeOption(options, "since"),
+				Until:   parseTimeOption(options, "until"),
+				Profile: options["profile"],
+				Type:    options["type"],
+			}
+			history(options["config"], query, jsonOutput)
+			return 0
+		})
+}
+
+// parseTimeOption reads a date, or a duration before now, zero when not set.
+func parseTimeOption(options map[string]string, name string) time.Time {
+	value := options[name]
+	if len(value) == 0 {
+		return time.Time{}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration)
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	cliFatalf(options["json"] == "true", "Invalid --%s option: %s", name, value)
+	return time.Time{}
+}
+
+func history(customConfigPath string, query JournalQuery, jsonOutput bool) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load configuration: %s", err.Error())
+	}
+	entries, err := ReadJournal(config.JournalPath, query)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot read journal: %s", err.Error())
+	}
+	if jsonOutput {
+		if entries == nil {
+			entries = []JournalEntry{}
+		}
+		json_bytes, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			cliFatalf(true, "Cannot serialize to json: %s", err.Error())
+		}
+		fmt.Println(string(json_bytes))
+		return
+	}
+	if len(entries) == 0 {
+		log.Println("* no deletion *")
+	}
+	for _, entry := range entries {
+		object := entry.Id
+		if len(entry.Name) > 0 && entry.Name != entry.Id {
+			object = fmt.Sprintf("%s (%s)", entry.Id, entry.Name)
+		}
+		result := entry.Result
+		if len(entry.Error) > 0 {
+			result = fmt.Sprintf("%s [%s] %s", entry.Result, entry.Kind, entry.Error)
+		}
+		log.Printf(
+			"%s %s %s (%s) %s %s: %s\n",
+			entry.Time.Local().Format(time.DateTime),
+			entry.Command,
+			entry.Profile,
+			entry.Provider,
+			entry.Type,
+			object,
+			result,
+		)
+	}
+}
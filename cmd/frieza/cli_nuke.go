@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"log"
-	"strings"
+	"os"
 	"time"
 
 	. "github.com/outscale/frieza/internal/common"
@@ -13,41 +13,40 @@ import (
 func cliNuke() cli.Command {
 	return cli.NewCommand("nuke", "delete ALL resources of specified profiles").
 		WithOption(cli.NewOption("plan", "Only show what resource would be deleted").WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("out", "Save the plan in this file to delete its resources later with apply (implies --plan)").WithType(cli.TypeString)).
 		WithOption(cli.NewOption("timeout", "Exit with error after a specific duration (ex: 30s, 5m, 1.5h)").WithType(cli.TypeString)).
 		WithOption(cli.NewOption("only-resource-types", "Remove only theses resource types (separated by ','). You can see all resource types in the description of the provider.").WithType(cli.TypeString)).
 		WithOption(cli.NewOption("exclude-resource-types", "Remove all except theses resource types (separated by ','). You can see all resource types in the description of the provider.").WithType(cli.TypeString)).
+		WithOption(cliTag()).
+		WithOption(cliExcludeTag()).
+		WithOption(cliMatch()).
+		WithOption(cliExcludeMatch()).
+		WithOption(cli.NewOption("older-than", "Remove only resources created before this duration (ex: 30m, 72h). Resources without creation date are ignored.").WithType(cli.TypeString)).
 		WithOption(cliJson()).
+		WithOption(cliParallelism()).
+		WithOption(cliDestroyParallelism()).
 		WithOption(cli.NewOption("auto-approve", "Approve resource deletion without confirmation").WithType(cli.TypeBool)).
 		WithOption(cliConfigPath()).
 		WithOption(cliDebug()).
 		WithArg(cli.NewArg("profile", "one or more profile").AsOptional()).
 		WithAction(func(args []string, options map[string]string) int {
 			setupDebug(options)
-			plan := options["plan"] == "true"
+			planPath := options["out"]
+			plan := options["plan"] == "true" || len(planPath) > 0
 			autoApprove := options["auto-approve"] == "true"
 			jsonOutput := options["json"] == "true"
-			timeout := "10m"
-			if len(options["timeout"]) > 0 {
-				timeout = options["timeout"]
-			}
+			timeout := parseTimeout(options, jsonOutput)
 
-			var resourcesTypeFilterPtr *ResourceFilterEnvelope
-			if len(options["only-resource-types"]) > 0 && len(options["exclude-resource-types"]) > 0 {
-				cliFatalf(true, "Cannot use --only-resource-types option with --exclude-resource-types")
-			}
-			if len(options["only-resource-types"]) > 0 {
-				resourcesTypeFilterPtr = NewResourceFilterOnly(strings.Split(options["only-resource-types"], ","))
-			}
-			if len(options["exclude-resource-types"]) > 0 {
-				resourcesTypeFilterPtr = NewResourceFilterExclude(strings.Split(options["exclude-resource-types"], ","))
-			}
+			resourcesTypeFilterPtr := combineFilters(parseResourceFilter(options), parseAgeOption(options, "older-than"))
 
-			nuke(options["config"], args, plan, autoApprove, jsonOutput, timeout, resourcesTypeFilterPtr)
+			parallelism := parseParallelism(options)
+
+			nuke(options["config"], args, plan, planPath, autoApprove, jsonOutput, timeout, parallelism, parseDestroyParallelism(options), resourcesTypeFilterPtr)
 			return 0
 		})
 }
 
-func nuke(customConfigPath string, profiles []string, plan bool, autoApprove bool, jsonOutput bool, timeout string, resourceFilter *ResourceFilterEnvelope) {
+func nuke(customConfigPath string, profiles []string, plan bool, planPath string, autoApprove bool, jsonOutput bool, timeout time.Duration, parallelism int, destroyParallelism int, resourceFilter *ResourceFilterEnvelope) {
 	if jsonOutput && !autoApprove {
 		cliFatalf(true, "Cannot use --json option without --auto-approve")
 	}
@@ -69,10 +68,12 @@ func nuke(customConfigPath string, profiles []string, plan bool, autoApprove boo
 	if err != nil {
 		cliFatalf(jsonOutput, "Cannot load configuration: %s", err.Error())
 	}
+	requireSignedPlan(config, plan, jsonOutput)
 
 	ctx := context.Background()
 
-	destroyer := NewDestroyer()
+	var targets []InventoryTarget
+	var targetProfiles []*Profile
 	for profileName := range uniqueProfiles {
 		profile, err := config.GetProfile(profileName)
 		if err != nil {
@@ -82,16 +83,32 @@ func nuke(customConfigPath string, profiles []string, plan bool, autoApprove boo
 		if err != nil {
 			cliFatalf(jsonOutput, "Error intializing profile %s: %s", profileName, err.Error())
 		}
-		for _, provider := range providers {
-			objectsToDelete, err := ReadObjects(ctx, &provider, resourceFilter)
-			if err != nil {
-				log.Fatalf("Error reading objects: %v", err)
+		for i := range providers {
+			target := newProfileTarget(profile, &providers[i], resourceFilter)
+			// Saved plans keep the tags of objects.
+			if len(planPath) == 0 {
+				target = skipUnusedTags(target, config, profile)
 			}
-			destroyer.add(profile, &provider, &objectsToDelete)
+			targets = append(targets, target)
+			targetProfiles = append(targetProfiles, profile)
 		}
 	}
+	checkPatternTypes(targets, jsonOutput)
+	warnUntagged(targets, jsonOutput)
+	inventory, err := newInventory(parallelism, config).Collect(ctx, targets)
+	if err != nil {
+		log.Fatalf("Error reading objects: %v", err)
+	}
+
+	destroyer := NewDestroyer(config, "nuke")
+	for i, target := range targets {
+		destroyer.add(targetProfiles[i], target.Provider, &inventory[i].Objects, inventory[i].Skipped)
+	}
 
 	destroyer.print(jsonOutput)
+	if len(planPath) > 0 {
+		destroyer.savePlan(planPath, "nuke", "", resourceFilter, jsonOutput)
+	}
 	if plan {
 		return
 	}
@@ -104,12 +121,13 @@ func nuke(customConfigPath string, profiles []string, plan bool, autoApprove boo
 		log.Fatal("Nuke canceled")
 	}
 
-	tout, err := time.ParseDuration(timeout)
-	if err != nil {
-		log.Fatal("Could not parse timeout: %w", err)
-	}
-	ctx, cancel := context.WithTimeout(ctx, tout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	destroyer.run(ctx)
+	report := destroyer.run(ctx, destroyParallelism)
+	report.print(jsonOutput)
+	if code := report.exitCode(); code != 0 {
+		cancel()
+		os.Exit(code)
+	}
 }
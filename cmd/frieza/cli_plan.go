@@ -0,0 +1,180 @@
+package main
+
+import (
+	"log"
+	"slices"
+
+	. "github.com/outscale/frieza/internal/common"
+	"github.com/teris-io/cli"
+)
+
+func cliPlan() cli.Command {
+	return cli.NewCommand("plan", "sign and verify plans saved with --out").
+		WithCommand(cliPlanKeygen()).
+		WithCommand(cliPlanSign()).
+		WithCommand(cliPlanVerify()).
+		WithCommand(cliPlanTrust()).
+		WithCommand(cliPlanUntrust())
+}
+
+func cliPlanKeygen() cli.Command {
+	return cli.NewCommand("keygen", "create an ed25519 private key to sign plans and show its public key").
+		WithArg(cli.NewArg("key_path", "path of the new private key")).
+		WithAction(func(args []string, options map[string]string) int {
+			publicKey, err := GenerateSigningKey(args[0])
+			if err != nil {
+				log.Fatalf("Cannot create key: %s", err.Error())
+			}
+			log.Printf("Public key: %s\n", EncodePublicKey(publicKey))
+			return 0
+		})
+}
+
+func cliPlanSign() cli.Command {
+	return cli.NewCommand("sign", "approve a plan by signing it").
+		WithArg(cli.NewArg("plan", "plan file")).
+		WithOption(cli.NewOption("key", "path of the ed25519 private key (PKCS #8 PEM)").WithType(cli.TypeString)).
+		WithAction(func(args []string, options map[string]string) int {
+			planSign(args[0], options["key"])
+			return 0
+		})
+}
+
+func cliPlanVerify() cli.Command {
+	return cli.NewCommand("verify", "show plan signatures and check them against trusted keys").
+		WithArg(cli.NewArg("plan", "plan file")).
+		WithOption(cliConfigPath()).
+		WithAction(func(args []string, options map[string]string) int {
+			planVerify(options["config"], args[0])
+			return 0
+		})
+}
+
+func cliPlanTrust() cli.Command {
+	return cli.NewCommand("trust", "accept signatures of a public key").
+		WithArg(cli.NewArg("name", "name of the key owner")).
+		WithArg(cli.NewArg("public_key", "base64 public key, as shown by keygen")).
+		WithOption(cliConfigPath()).
+		WithAction(func(args []string, options map[string]string) int {
+			planTrust(options["config"], args[0], args[1])
+			return 0
+		})
+}
+
+func cliPlanUntrust() cli.Command {
+	return cli.NewCommand("untrust", "stop accepting signatures of a public key").
+		WithArg(cli.NewArg("name", "name of the key owner")).
+		WithOption(cliConfigPath()).
+		WithAction(func(args []string, options map[string]string) int {
+			planUntrust(options["config"], args[0])
+			return 0
+		})
+}
+
+func planSign(planPath string, keyPath string) {
+	if len(keyPath) == 0 {
+		log.Fatal("No private key provided, use --key option")
+	}
+	key, err := LoadSigningKey(keyPath)
+	if err != nil {
+		log.Fatalf("Cannot load private key %s: %s", keyPath, err.Error())
+	}
+	plan, err := PlanLoad(planPath)
+	if err != nil {
+		log.Fatalf("Cannot load plan %s: %s", planPath, err.Error())
+	}
+	if err = plan.Sign(key); err != nil {
+		log.Fatalf("Cannot sign plan %s: %s", planPath, err.Error())
+	}
+	if err = plan.Write(planPath); err != nil {
+		log.Fatalf("Cannot save plan %s: %s", planPath, err.Error())
+	}
+}
+
+func planVerify(customConfigPath string, planPath string) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	plan, err := PlanLoad(planPath)
+	if err != nil {
+		log.Fatalf("Cannot load plan %s: %s", planPath, err.Error())
+	}
+	log.Printf("%s plan made at %s\n", plan.Command, plan.CreatedAt)
+	payload, err := plan.payload()
+	if err != nil {
+		log.Fatalf("Cannot verify plan %s: %s", planPath, err.Error())
+	}
+	if len(plan.Signatures) == 0 {
+		log.Println("* no signature *")
+	}
+	for _, signature := range plan.Signatures {
+		signer := "untrusted key " + signature.PublicKey
+		if key := config.TrustedKey(signature.PublicKey); key != nil {
+			signer = key.Name
+		}
+		status := "valid"
+		if err := signature.Verify(payload); err != nil {
+			status = err.Error()
+		}
+		log.Printf("  - %s, signed at %s: %s\n", signer, signature.SignedAt, status)
+	}
+	signers, err := plan.Signers(config)
+	if err != nil {
+		log.Fatalf("Plan %s cannot be applied: %s", planPath, err.Error())
+	}
+	if len(signers) < config.RequiredSignatures {
+		log.Fatalf("Plan %s is signed by %d trusted keys, %d are required", planPath, len(signers), config.RequiredSignatures)
+	}
+	log.Printf("Plan %s is signed by %d trusted keys, %d are required\n", planPath, len(signers), config.RequiredSignatures)
+}
+
+func planTrust(customConfigPath string, name string, publicKey string) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	if _, err := ParsePublicKey(publicKey); err != nil {
+		log.Fatal(err.Error())
+	}
+	if key := config.TrustedKey(publicKey); key != nil {
+		log.Fatalf("Key is already trusted as %s", key.Name)
+	}
+	if slices.ContainsFunc(config.TrustedKeys, func(key TrustedKey) bool { return key.Name == name }) {
+		log.Fatalf("A key named %s is already trusted", name)
+	}
+	config.TrustedKeys = append(config.TrustedKeys, TrustedKey{Name: name, PublicKey: publicKey})
+	if err := config.Write(configPath); err != nil {
+		log.Fatalf("Cannot save configuration file: %s", err.Error())
+	}
+}
+
+func planUntrust(customConfigPath string, name string) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	count := len(config.TrustedKeys)
+	config.TrustedKeys = slices.DeleteFunc(config.TrustedKeys, func(key TrustedKey) bool { return key.Name == name })
+	if len(config.TrustedKeys) == count {
+		log.Fatalf("No trusted key named %s", name)
+	}
+	if config.RequiredSignatures > len(config.TrustedKeys) {
+		log.Printf("Warning: %d signatures are required but only %d keys are trusted, no plan can be applied\n", config.RequiredSignatures, len(config.TrustedKeys))
+	}
+	if err := config.Write(configPath); err != nil {
+		log.Fatalf("Cannot save configuration file: %s", err.Error())
+	}
+}
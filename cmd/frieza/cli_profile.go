@@ -47,8 +47,9 @@ func cliProfileDescribe() cli.Command {
 
 func cliProfileNew() cli.Command {
 	cmd := cli.NewCommand("new", "create new profile")
-	for _, providerCli := range providersCli {
-		name, c := providerCli()
+	for _, registration := range RegisteredProviders() {
+		name := registration.Name
+		c := providerCli(registration)
 		c.WithArg(cli.NewArg("profile", "frieza profile name")).
 			WithOption(cliConfigPath()).
 			WithOption(cliDebug()).
@@ -212,8 +213,8 @@ func removeProfileIndex(s []Profile, index int) []Profile {
 func cliProfileAddProvider() cli.Command {
 	cmd := cli.NewCommand("add-provider", "add a provider to an existing profile")
 
-	for _, providerCli := range providersCli {
-		providerName, _ := providerCli()
+	for _, registration := range RegisteredProviders() {
+		providerName := registration.Name
 		subCmd := cli.NewCommand(providerName, "add "+providerName+" provider").
 			WithArg(cli.NewArg("profile_name", "profile's name")).
 			WithOption(cliConfigPath()).
@@ -232,8 +233,8 @@ func cliProfileAddProvider() cli.Command {
 func cliProfileRemoveProvider() cli.Command {
 	cmd := cli.NewCommand("remove-provider", "remove a provider from a profile")
 
-	for _, providerCli := range providersCli {
-		providerName, _ := providerCli()
+	for _, registration := range RegisteredProviders() {
+		providerName := registration.Name
 		subCmd := cli.NewCommand(providerName, "remove "+providerName+" provider").
 			WithArg(cli.NewArg("profile_name", "profile's name")).
 			WithOption(cliConfigPath()).
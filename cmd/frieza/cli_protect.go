@@ -0,0 +1,158 @@
+package main
+
+import (
+	"log"
+	"slices"
+
+	. "github.com/outscale/frieza/internal/common"
+	"github.com/teris-io/cli"
+)
+
+func cliProtect() cli.Command {
+	return cli.NewCommand("protect", "manage resources frieza must never delete").
+		WithCommand(cliProtectLs()).
+		WithCommand(cliProtectAdd()).
+		WithCommand(cliProtectRm())
+}
+
+func cliProtectRuleOptions(cmd cli.Command) cli.Command {
+	return cmd.
+		WithOption(cli.NewOption("profile", "Apply the rule to this profile only (all profiles by default)").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("type", "Apply the rule to this resource type only").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("id", "Protect the resource having this id").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("name", "Protect resources whose name match this glob pattern (ex: bastion-*)").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("tag", "Protect resources having all these tags (key=value separated by ',')").WithType(cli.TypeString)).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug())
+}
+
+func cliProtectLs() cli.Command {
+	return cli.NewCommand("list", "list protected resources").
+		WithShortcut("ls").
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			protectLs(options["config"])
+			return 0
+		})
+}
+
+func cliProtectAdd() cli.Command {
+	return cliProtectRuleOptions(cli.NewCommand("add", "protect resources from deletion")).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			protectAdd(options["config"], options["profile"], parseProtectRule(options))
+			return 0
+		})
+}
+
+func cliProtectRm() cli.Command {
+	return cliProtectRuleOptions(cli.NewCommand("remove", "remove a protection rule (same options as add)")).
+		WithShortcut("rm").
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			protectRm(options["config"], options["profile"], parseProtectRule(options))
+			return 0
+		})
+}
+
+func parseProtectRule(options map[string]string) ProtectRule {
+	rule := ProtectRule{
+		Type: options["type"],
+		Id:   options["id"],
+		Name: options["name"],
+	}
+	if len(options["tag"]) > 0 {
+		tags, err := ParseTags(options["tag"])
+		if err != nil {
+			log.Fatalf("Invalid --tag option: %s", err.Error())
+		}
+		rule.Tags = tags
+	}
+	if err := rule.Validate(); err != nil {
+		log.Fatalf("Invalid rule: %s", err.Error())
+	}
+	return rule
+}
+
+// protectedRules returns the rules of profileName, or the global ones when
+// profileName is empty.
+func protectedRules(config *Config, profileName string) *[]ProtectRule {
+	if len(profileName) == 0 {
+		return &config.Protected
+	}
+	for i := range config.Profiles {
+		if config.Profiles[i].Name == profileName {
+			return &config.Profiles[i].Protected
+		}
+	}
+	log.Fatalf("Profile %s not found", profileName)
+	return nil
+}
+
+func protectLs(customConfigPath string) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	log.Println("all profiles:")
+	if len(config.Protected) == 0 {
+		log.Println("  * no rule *")
+	}
+	for _, rule := range config.Protected {
+		log.Printf("  - %s\n", rule)
+	}
+	for _, profile := range config.Profiles {
+		if len(profile.Protected) == 0 {
+			continue
+		}
+		log.Printf("profile %s:\n", profile.Name)
+		for _, rule := range profile.Protected {
+			log.Printf("  - %s\n", rule)
+		}
+	}
+}
+
+func protectAdd(customConfigPath string, profileName string, rule ProtectRule) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	rules := protectedRules(config, profileName)
+	if slices.ContainsFunc(*rules, rule.Equal) {
+		log.Fatalf("Rule %s already exists", rule)
+	}
+	*rules = append(*rules, rule)
+	if err := config.Write(configPath); err != nil {
+		log.Fatalf("Cannot save configuration file: %s", err.Error())
+	}
+}
+
+func protectRm(customConfigPath string, profileName string, rule ProtectRule) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	rules := protectedRules(config, profileName)
+	index := slices.IndexFunc(*rules, rule.Equal)
+	if index < 0 {
+		log.Fatalf("Rule %s not found", rule)
+	}
+	*rules = slices.Delete(*rules, index, index+1)
+	if err := config.Write(configPath); err != nil {
+		log.Fatalf("Cannot save configuration file: %s", err.Error())
+	}
+}
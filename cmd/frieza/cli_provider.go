@@ -1,23 +1,28 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
 
+	. "github.com/outscale/frieza/internal/common"
+	"github.com/outscale/frieza/internal/providers/plugin"
 	"github.com/teris-io/cli"
 )
 
 func cliProvider() cli.Command {
 	return cli.NewCommand("provider", "show supported providers and their features").
 		WithCommand(cliProviderLs()).
-		WithCommand(cliProviderDescribe())
+		WithCommand(cliProviderDescribe()).
+		WithCommand(cliProviderConformance())
 }
 
 func cliProviderLs() cli.Command {
 	return cli.NewCommand("list", "list providers").
 		WithShortcut("ls").
 		WithAction(func(args []string, options map[string]string) int {
-			for providerName := range providersTypes {
-				fmt.Println(providerName)
+			for _, registration := range RegisteredProviders() {
+				fmt.Println(registration.Name)
 			}
 			return 0
 		})
@@ -28,10 +33,47 @@ func cliProviderDescribe() cli.Command {
 		WithShortcut("desc").
 		WithArg(cli.NewArg("provider_name", "provider to describe")).
 		WithAction(func(args []string, options map[string]string) int {
-			providerName := args[0]
-			for _, providerType := range providersTypes[providerName] {
+			registration, found := LookupProvider(args[0])
+			if !found {
+				log.Fatalf("Provider %s not found", args[0])
+			}
+			types := registration.Types()
+			for _, providerType := range types {
 				fmt.Println(providerType)
 			}
+			if len(types) > 0 && len(registration.Config) > 0 {
+				fmt.Println()
+			}
+			if len(registration.Config) > 0 {
+				fmt.Println("profile options:")
+			}
+			for _, option := range registration.Config {
+				fmt.Printf("  --%s: %s\n", option.Name, option.Description)
+			}
 			return 0
 		})
 }
+
+func cliProviderConformance() cli.Command {
+	return cli.NewCommand("conformance", "check that a plugin executable follows the plugin protocol").
+		WithOption(cli.NewOption("path", "plugin executable").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("args", "plugin arguments (separated by spaces)").WithType(cli.TypeString)).
+		WithOption(cli.NewOption("destructive", "Also delete all objects of the plugin and check they are gone").WithType(cli.TypeBool)).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			providerConformance(options["path"], options["args"], options["destructive"] == "true")
+			return 0
+		})
+}
+
+func providerConformance(path string, args string, destructive bool) {
+	provider, err := plugin.New(ProviderConfig{"path": path, "args": args}, GlobalCliOptions.debug)
+	if err != nil {
+		log.Fatalf("Cannot start plugin: %s", err.Error())
+	}
+	if err := plugin.Conformance(context.Background(), provider, destructive, log.Printf); err != nil {
+		log.Fatalf("Plugin conformance failed: %s", err.Error())
+	}
+	log.Println("Plugin conformance passed")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+	"github.com/teris-io/cli"
+)
+
+func cliResume() cli.Command {
+	return cli.NewCommand("resume", "continue an interrupted clean, nuke or apply run, list them without run id").
+		WithArg(cli.NewArg("run_id", "run to continue").AsOptional()).
+		WithOption(cli.NewOption("timeout", "Exit with error after a specific duration (ex: 30s, 5m, 1.5h)").WithType(cli.TypeString)).
+		WithOption(cliDestroyParallelism()).
+		WithOption(cliJson()).
+		WithOption(cli.NewOption("auto-approve", "Approve resource deletion without confirmation").WithType(cli.TypeBool)).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			if len(args) == 0 {
+				runLs(options["config"])
+				return 0
+			}
+			autoApprove := options["auto-approve"] == "true"
+			jsonOutput := options["json"] == "true"
+			timeout := parseTimeout(options, jsonOutput)
+			resume(options["config"], args[0], autoApprove, jsonOutput, timeout, parseDestroyParallelism(options))
+			return 0
+		})
+}
+
+func runLs(customConfigPath string) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	ids, err := RunList(config)
+	if err != nil {
+		log.Fatalf("Cannot list runs: %s", err.Error())
+	}
+	if len(ids) == 0 {
+		log.Println("* no run to resume *")
+	}
+	for _, id := range ids {
+		run, err := RunLoad(config, id)
+		if err != nil {
+			log.Printf("%s: %s\n", id, err.Error())
+			continue
+		}
+		objectsCount := 0
+		for _, target := range run.Targets {
+			objectsCount += ObjectsCount(target.Objects)
+		}
+		log.Printf("%s: %s started at %s, %d objects left\n", id, run.Command, run.StartedAt.Local().Format(time.DateTime), objectsCount)
+	}
+}
+
+func resume(customConfigPath string, runId string, autoApprove bool, jsonOutput bool, timeout time.Duration, destroyParallelism int) {
+	if jsonOutput && !autoApprove {
+		cliFatalf(true, "Cannot use --json option without --auto-approve")
+	}
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load configuration: %s", err.Error())
+	}
+	run, err := RunLoad(config, runId)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load run %s: %s", runId, err.Error())
+	}
+	if config.RequiredSignatures > 0 {
+		if err := run.checkSigned(config); err != nil {
+			cliFatalf(jsonOutput, "Cannot resume run %s: %s", runId, err.Error())
+		}
+	}
+	destroyer, err := run.destroyer(config)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot resume run %s: %s", runId, err.Error())
+	}
+
+	destroyer.print(jsonOutput)
+	if jsonOutput {
+		disableLogs()
+	}
+	message := "Do you really want to delete resources left by this run?\n" +
+		"  Frieza will delete all resources shown above."
+	if !confirmAction(&message, autoApprove) {
+		log.Fatal("Resume canceled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	report := destroyer.run(ctx, destroyParallelism)
+	report.print(jsonOutput)
+	if code := report.exitCode(); code != 0 {
+		cancel()
+		os.Exit(code)
+	}
+}
@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
-	"os"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,7 +23,12 @@ func cliSnapshot() cli.Command {
 		WithCommand(cliSnapshotLs()).
 		WithCommand(cliSnapshotDescribe()).
 		WithCommand(cliSnapshotRm()).
-		WithCommand(cliSnapshotUpdate())
+		WithCommand(cliSnapshotUpdate()).
+		WithCommand(cliSnapshotDiff()).
+		WithCommand(cliSnapshotMerge()).
+		WithCommand(cliSnapshotCopy()).
+		WithCommand(cliSnapshotRename()).
+		WithCommand(cliSnapshotForget())
 }
 
 func cliSnapshotNew() cli.Command {
@@ -26,6 +36,11 @@ func cliSnapshotNew() cli.Command {
 		WithArg(cli.NewArg("snapshot_name", "snapshot name")).
 		WithOption(cli.NewOption("only-resource-types", "Remove only theses resource types (separated by ','). You can see all resource types in the description of the provider.").WithType(cli.TypeString)).
 		WithOption(cli.NewOption("exclude-resource-types", "Remove all except theses resource types (separated by ','). You can see all resource types in the description of the provider.").WithType(cli.TypeString)).
+		WithOption(cliTag()).
+		WithOption(cliExcludeTag()).
+		WithOption(cliMatch()).
+		WithOption(cliExcludeMatch()).
+		WithOption(cliParallelism()).
 		WithOption(cliConfigPath()).
 		WithOption(cliDebug()).
 		WithArg(cli.NewArg("profile", "one or more profile to snapshot").AsOptional()).
@@ -81,10 +96,90 @@ func cliSnapshotUpdate() cli.Command {
 		WithOption(cliConfigPath()).
 		WithOption(cliDebug()).
 		WithOption(cli.NewOption("incremental", "update snapshot incrementally").WithType(cli.TypeBool).WithChar('i')).
+		WithOption(cli.NewOption("prune", "also remove objects which no longer exist").WithType(cli.TypeBool)).
+		WithOption(cliParallelism()).
 		WithAction(func(args []string, options map[string]string) int {
 			incrementalUpdate := options["incremental"] == "true"
 			setupDebug(options)
-			snapshotUpdate(options["config"], &args[0], incrementalUpdate)
+			snapshotUpdate(options["config"], &args[0], incrementalUpdate, options["prune"] == "true", parseParallelism(options))
+			return 0
+		})
+}
+
+func cliSnapshotDiff() cli.Command {
+	return cli.NewCommand("diff", "show objects created, deleted and retained between two snapshots").
+		WithArg(cli.NewArg("snapshot_a", "older snapshot")).
+		WithArg(cli.NewArg("snapshot_b", "newer snapshot")).
+		WithOption(cli.NewOption("summary", "only show the number of objects per type").WithType(cli.TypeBool)).
+		WithOption(cliJson()).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			snapshotDiff(options["config"], args[0], args[1], options["summary"] == "true", options["json"] == "true")
+			return 0
+		})
+}
+
+func cliSnapshotMerge() cli.Command {
+	return cli.NewCommand("merge", "create a snapshot containing the objects of several snapshots").
+		WithArg(cli.NewArg("output_snapshot", "name of the merged snapshot")).
+		WithArg(cli.NewArg("snapshot", "two or more snapshots to merge").AsOptional()).
+		WithOption(cli.NewOption("force", "merge snapshots having different filters, keeping only what all filters select").WithType(cli.TypeBool)).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			snapshotMerge(options["config"], args[0], args[1:], options["force"] == "true")
+			return 0
+		})
+}
+
+func cliSnapshotCopy() cli.Command {
+	return cli.NewCommand("copy", "copy a snapshot under a new name").
+		WithShortcut("cp").
+		WithArg(cli.NewArg("snapshot_name", "snapshot to copy")).
+		WithArg(cli.NewArg("new_snapshot_name", "name of the copy")).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			snapshotCopy(options["config"], args[0], args[1], false)
+			return 0
+		})
+}
+
+func cliSnapshotRename() cli.Command {
+	return cli.NewCommand("rename", "rename a snapshot").
+		WithShortcut("mv").
+		WithArg(cli.NewArg("snapshot_name", "snapshot to rename")).
+		WithArg(cli.NewArg("new_snapshot_name", "new name of the snapshot")).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			snapshotCopy(options["config"], args[0], args[1], true)
+			return 0
+		})
+}
+
+func cliSnapshotForget() cli.Command {
+	return cli.NewCommand("forget", "remove objects from a snapshot, so that clean deletes them").
+		WithArg(cli.NewArg("snapshot_name", "snapshot name")).
+		WithOption(cli.NewOption("profile", "only forget objects of this profile")).
+		WithOption(cli.NewOption("type", "only forget objects of these types (separated by ',')")).
+		WithOption(cli.NewOption("id", "only forget objects having one of these ids (separated by ',')")).
+		WithOption(cliMatch()).
+		WithOption(cliExcludeMatch()).
+		WithOption(cliTag()).
+		WithOption(cliExcludeTag()).
+		WithOption(cli.NewOption("incremental", "choose objects to forget one by one").WithType(cli.TypeBool).WithChar('i')).
+		WithOption(cli.NewOption("auto-approve", "Forget objects without confirmation").WithType(cli.TypeBool)).
+		WithOption(cliConfigPath()).
+		WithOption(cliDebug()).
+		WithAction(func(args []string, options map[string]string) int {
+			setupDebug(options)
+			snapshotForget(options["config"], args[0], options)
 			return 0
 		})
 }
@@ -103,48 +198,32 @@ func snapshotNew(customConfigPath string, args []string, options map[string]stri
 	if err != nil {
 		log.Fatalf("Cannot load configuration: %s", err.Error())
 	}
-	if _, err = SnapshotLoad(snapshotName, config); err == nil {
-		log.Fatalf("Snapshot %s already exist", snapshotName)
-	}
+	checkSnapshotMissing(snapshotName, config)
 
-	var resourcesTypeFilterPtr *ResourceFilterEnvelope
-	if len(options["only-resource-types"]) > 0 && len(options["exclude-resource-types"]) > 0 {
-		cliFatalf(true, "Cannot use --only-resource-types option with --exclude-resource-types")
-	}
-	if len(options["only-resource-types"]) > 0 {
-		resourcesTypeFilterPtr = NewResourceFilterOnly(strings.Split(options["only-resource-types"], ","))
-	}
-	if len(options["exclude-resource-types"]) > 0 {
-		resourcesTypeFilterPtr = NewResourceFilterExclude(strings.Split(options["exclude-resource-types"], ","))
-	}
+	resourcesTypeFilterPtr := parseResourceFilter(options)
 
-	var providers []Provider
+	var targets []InventoryTarget
 	var profiles []string
 	for _, profileName := range profileNames {
-		found := false
-		for _, profile := range config.Profiles {
-			if profileName == profile.Name {
-				profileProviders, err := ProviderNew(profile)
-				if err != nil {
-					log.Fatalf("Cannot initialize profile %s: %s", profile.Name, err.Error())
-				}
-				providers = append(providers, profileProviders...)
-				for range profileProviders {
-					profiles = append(profiles, profile.Name)
-				}
-				found = true
-				break
-			}
-		}
-		if !found {
+		profile, err := config.GetProfile(profileName)
+		if err != nil {
 			log.Fatalf("Profile %s not found", profileName)
 		}
+		profileProviders, err := ProviderNew(*profile)
+		if err != nil {
+			log.Fatalf("Cannot initialize profile %s: %s", profile.Name, err.Error())
+		}
+		for i := range profileProviders {
+			targets = append(targets, newProfileTarget(profile, &profileProviders[i], resourcesTypeFilterPtr))
+			profiles = append(profiles, profile.Name)
+		}
 	}
+	checkPatternTypes(targets, false)
 
 	ctx := context.Background()
 
-	for i, provider := range providers {
-		if err := provider.AuthTest(ctx); err != nil {
+	for i, target := range targets {
+		if err := (*target.Provider).AuthTest(ctx); err != nil {
 			log.Fatalf("Provider test failed for profile %s: %s", profiles[i], err.Error())
 		}
 	}
@@ -157,15 +236,16 @@ func snapshotNew(customConfigPath string, args []string, options map[string]stri
 		Config:  config,
 		Filters: resourcesTypeFilterPtr,
 	}
-	for i, provider := range providers {
-		objs, err := ReadObjects(ctx, &provider, resourcesTypeFilterPtr)
-		if err != nil {
-			log.Fatalf("Error reading objects: %v\n", err)
-		}
+	warnUntagged(targets, false)
+	objects, err := newInventory(parseParallelism(options), config).Read(ctx, targets)
+	if err != nil {
+		log.Fatalf("Error reading objects: %v\n", err)
+	}
+	for i, target := range targets {
 		snapshot.Data = append(snapshot.Data, SnapshotData{
 			Profile:  profiles[i],
-			Provider: provider.Name(),
-			Objects:  objs,
+			Provider: (*target.Provider).Name(),
+			Objects:  objects[i],
 		})
 	}
 	if err = snapshot.Write(); err != nil {
@@ -182,15 +262,11 @@ func snapshotLs(customConfigPath string) {
 	if err != nil {
 		log.Fatalf("Cannot load configuration: %s", err.Error())
 	}
-	files, err := os.ReadDir(config.SnapshotFolderPath)
+	snapshotNames, err := SnapshotList(config)
 	if err != nil {
 		log.Fatalf("Error while listing snapshots: %s", err.Error())
 	}
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-		snapshotName := strings.TrimSuffix(file.Name(), ".json")
+	for _, snapshotName := range snapshotNames {
 		if snapshot, err := SnapshotLoad(snapshotName, config); err == nil {
 			log.Println(snapshot.Name)
 		}
@@ -231,7 +307,7 @@ func snapshotRm(customConfigPath string, snapshotName *string) {
 	}
 }
 
-func snapshotUpdate(customConfigPath string, snapshotName *string, incrementalUpdate bool) {
+func snapshotUpdate(customConfigPath string, snapshotName *string, incrementalUpdate bool, prune bool, parallelism int) {
 	var configPath *string
 	if len(customConfigPath) > 0 {
 		configPath = &customConfigPath
@@ -247,6 +323,8 @@ func snapshotUpdate(customConfigPath string, snapshotName *string, incrementalUp
 
 	ctx := context.Background()
 
+	var targets []InventoryTarget
+	var targetData []SnapshotData
 	for _, data := range snapshot.Data {
 		profile, err := config.GetProfile(data.Profile)
 		if err != nil {
@@ -257,40 +335,73 @@ func snapshotUpdate(customConfigPath string, snapshotName *string, incrementalUp
 			log.Fatalf("Error intializing profile %s: %s", data.Profile, err.Error())
 		}
 
-		for _, provider := range providers {
+		for i, provider := range providers {
+			if provider.Name() != data.Provider {
+				continue
+			}
 			if err := provider.AuthTest(ctx); err != nil {
 				log.Fatalf("Provider %s test failed for profile %s: %s", provider.Name(), profile.Name, err.Error())
 			}
+			targets = append(targets, newProfileTarget(profile, &providers[i], snapshot.Filters))
+			targetData = append(targetData, data)
+		}
+	}
+	warnUntagged(targets, false)
+	results, err := newInventory(parallelism, config).Collect(ctx, targets)
+	if err != nil {
+		log.Fatalf("Error reading objects: %v\n", err)
+	}
 
-			objects, err := ReadObjects(ctx, &provider, snapshot.Filters)
-			if err != nil {
-				log.Fatalf("Error reading objects: %v\n", err)
-			}
-			diff := NewDiff()
-			diff.Build(&data.Objects, &objects)
-			for key, value := range diff.Created {
-				var objectToAdd []string
-				if incrementalUpdate {
-					incrementObject, err := incrementalChoice(key, value)
-					if err != nil {
-						log.Fatalf("Snapshot failed: %s", err.Error())
-					}
-
-					if incrementObject == nil {
-						log.Fatalf("Snapshot update cancels")
-					}
-					objectToAdd = append(objectToAdd, (*incrementObject)...)
-				} else {
-					objectToAdd = value
+	var prunedCount int
+	for i, data := range targetData {
+		diff := NewDiff()
+		diff.Build(&data.Objects, &results[i].Objects)
+		for key, value := range diff.Created {
+			var objectToAdd []Object
+			if incrementalUpdate {
+				incrementObject, err := incrementalChoice(incrementalAdd, key, value)
+				if err != nil {
+					log.Fatalf("Snapshot failed: %s", err.Error())
 				}
 
-				if snapshotValue, ok := data.Objects[key]; ok {
-					data.Objects[key] = append(snapshotValue, objectToAdd...)
-				} else {
-					data.Objects[key] = objectToAdd
+				if incrementObject == nil {
+					log.Fatalf("Snapshot update cancels")
 				}
+				objectToAdd = append(objectToAdd, (*incrementObject)...)
+			} else {
+				objectToAdd = value
+			}
+
+			if snapshotValue, ok := data.Objects[key]; ok {
+				data.Objects[key] = append(snapshotValue, objectToAdd...)
+			} else {
+				data.Objects[key] = objectToAdd
 			}
 		}
+		if !prune {
+			continue
+		}
+		pruned := Vanished(diff.Deleted, results[i])
+		if incrementalUpdate {
+			for _, typeName := range slices.Sorted(maps.Keys(pruned)) {
+				chosen, err := incrementalChoice(incrementalForget, typeName, pruned[typeName])
+				if err != nil {
+					log.Fatalf("Snapshot failed: %s", err.Error())
+				}
+				if chosen == nil {
+					log.Fatalf("Snapshot update cancels")
+				}
+				pruned[typeName] = *chosen
+			}
+		}
+		if count := data.Forget(pruned); count > 0 {
+			log.Printf("Objects pruned from profile %s (%s):\n", data.Profile, data.Provider)
+			log.Print(ObjectsPrint(targets[i].Provider, &pruned))
+			prunedCount += count
+		}
+	}
+	if prune {
+		log.Printf("%d objects pruned from snapshot %s\n", prunedCount, *snapshotName)
 	}
 
 	date := time.Now().UTC().String()
@@ -301,3 +412,332 @@ func snapshotUpdate(customConfigPath string, snapshotName *string, incrementalUp
 		log.Fatalf("Snapshot failed: %s", err.Error())
 	}
 }
+
+type snapshotDiffReport struct {
+	From  string             `json:"from"`
+	To    string             `json:"to"`
+	Diffs []SnapshotDataDiff `json:"diffs"`
+}
+
+type snapshotDiffSummary struct {
+	From  string                   `json:"from"`
+	To    string                   `json:"to"`
+	Diffs []snapshotDataDiffCounts `json:"diffs"`
+}
+
+type snapshotDataDiffCounts struct {
+	Profile  string                    `json:"profile"`
+	Provider string                    `json:"provider"`
+	Types    map[ObjectType]diffCounts `json:"types"`
+}
+
+type diffCounts struct {
+	Created  int `json:"created"`
+	Deleted  int `json:"deleted"`
+	Retained int `json:"retained"`
+}
+
+func snapshotDiff(customConfigPath string, nameA string, nameB string, summary bool, jsonOutput bool) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load configuration: %s", err.Error())
+	}
+	if summary {
+		snapshotDiffSummarize(config, nameA, nameB, jsonOutput)
+		return
+	}
+	snapshotA, err := SnapshotLoad(nameA, config)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load snapshot %s: %s", nameA, err.Error())
+	}
+	snapshotB, err := SnapshotLoad(nameB, config)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load snapshot %s: %s", nameB, err.Error())
+	}
+	diffs := SnapshotDiff(snapshotA, snapshotB)
+
+	if jsonOutput {
+		json_bytes, err := json.MarshalIndent(snapshotDiffReport{From: nameA, To: nameB, Diffs: diffs}, "", "  ")
+		if err != nil {
+			cliFatalf(true, "Cannot serialize to json: %s", err.Error())
+		}
+		fmt.Println(string(json_bytes))
+		return
+	}
+
+	for _, diff := range diffs {
+		log.Printf("Profile %s (%s):\n", diff.Profile, diff.Provider)
+		counts := countDiff(diff.Diff)
+		if len(counts) == 0 {
+			log.Println("* no object *")
+		}
+		for _, typeName := range slices.Sorted(maps.Keys(counts)) {
+			log.Printf("  %s:\n", typeName)
+			for _, object := range diff.Created[typeName] {
+				log.Printf("    + %s\n", object)
+			}
+			for _, object := range diff.Deleted[typeName] {
+				log.Printf("    - %s\n", object)
+			}
+			for _, object := range diff.Retained[typeName] {
+				log.Printf("    = %s\n", object)
+			}
+		}
+	}
+}
+
+// countDiff returns the number of objects of each type having any.
+func countDiff(diff *Diff) map[ObjectType]diffCounts {
+	counts := make(map[ObjectType]diffCounts)
+	for _, objects := range []Objects{diff.Created, diff.Deleted, diff.Retained} {
+		for typeName := range objects {
+			counts[typeName] = diffCounts{
+				Created:  len(diff.Created[typeName]),
+				Deleted:  len(diff.Deleted[typeName]),
+				Retained: len(diff.Retained[typeName]),
+			}
+		}
+	}
+	return counts
+}
+
+// snapshotDiffSummarize counts the differences between two snapshots from
+// their object identifiers, without loading their objects.
+func snapshotDiffSummarize(config *Config, nameA string, nameB string, jsonOutput bool) {
+	indexA, err := SnapshotIndexLoad(nameA, config)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load snapshot %s: %s", nameA, err.Error())
+	}
+	indexB, err := SnapshotIndexLoad(nameB, config)
+	if err != nil {
+		cliFatalf(jsonOutput, "Cannot load snapshot %s: %s", nameB, err.Error())
+	}
+	counts := countIndexDiff(indexA, indexB)
+	if jsonOutput {
+		json_bytes, err := json.MarshalIndent(snapshotDiffSummary{From: nameA, To: nameB, Diffs: counts}, "", "  ")
+		if err != nil {
+			cliFatalf(true, "Cannot serialize to json: %s", err.Error())
+		}
+		fmt.Println(string(json_bytes))
+		return
+	}
+	for _, dataCounts := range counts {
+		log.Printf("Profile %s (%s):\n", dataCounts.Profile, dataCounts.Provider)
+		if len(dataCounts.Types) == 0 {
+			log.Println("* no object *")
+		}
+		for _, typeName := range slices.Sorted(maps.Keys(dataCounts.Types)) {
+			count := dataCounts.Types[typeName]
+			log.Printf("  %s: %d created, %d deleted, %d retained\n", typeName, count.Created, count.Deleted, count.Retained)
+		}
+	}
+}
+
+// countIndexDiff counts objects like countDiff, per profile and provider in
+// the order of SnapshotDiff.
+func countIndexDiff(a *SnapshotIndex, b *SnapshotIndex) []snapshotDataDiffCounts {
+	var counts []snapshotDataDiffCounts
+	findData := func(index *SnapshotIndex, data SnapshotDataIndex) ObjectIdSets {
+		i := slices.IndexFunc(index.Data, func(other SnapshotDataIndex) bool {
+			return other.Profile == data.Profile && other.Provider == data.Provider
+		})
+		if i == -1 {
+			return nil
+		}
+		return index.Data[i].Ids
+	}
+	for _, dataA := range a.Data {
+		idsB := findData(b, dataA)
+		types := make(map[ObjectType]diffCounts)
+		for typeName, idsA := range dataA.Ids {
+			retained := 0
+			for id := range idsA {
+				if _, ok := idsB[typeName][id]; ok {
+					retained++
+				}
+			}
+			count := diffCounts{Deleted: len(idsA) - retained, Retained: retained}
+			if count.Deleted+count.Retained > 0 {
+				types[typeName] = count
+			}
+		}
+		for typeName, typeIdsB := range idsB {
+			count := types[typeName]
+			count.Created = len(typeIdsB) - count.Retained
+			if count.Created+count.Deleted+count.Retained > 0 {
+				types[typeName] = count
+			}
+		}
+		counts = append(counts, snapshotDataDiffCounts{Profile: dataA.Profile, Provider: dataA.Provider, Types: types})
+	}
+	for _, dataB := range b.Data {
+		if findData(a, dataB) != nil {
+			continue
+		}
+		types := make(map[ObjectType]diffCounts)
+		for typeName, idsB := range dataB.Ids {
+			if len(idsB) > 0 {
+				types[typeName] = diffCounts{Created: len(idsB)}
+			}
+		}
+		counts = append(counts, snapshotDataDiffCounts{Profile: dataB.Profile, Provider: dataB.Provider, Types: types})
+	}
+	return counts
+}
+
+func snapshotMerge(customConfigPath string, outputName string, names []string, force bool) {
+	if len(names) < 2 {
+		log.Fatal("At least two snapshots are needed to merge")
+	}
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	checkSnapshotMissing(outputName, config)
+	var snapshots []*Snapshot
+	for _, name := range names {
+		snapshot, err := SnapshotLoad(name, config)
+		if err != nil {
+			log.Fatalf("Cannot load snapshot %s: %s", name, err.Error())
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	merged, err := SnapshotMerge(outputName, snapshots, force, config)
+	if errors.Is(err, ErrIncompatibleFilters) {
+		log.Fatalf("Cannot merge snapshots: %s, use --force to merge anyway", err.Error())
+	}
+	if err != nil {
+		log.Fatalf("Cannot merge snapshots: %s", err.Error())
+	}
+	if err = merged.Write(); err != nil {
+		log.Fatalf("Cannot write snapshot %s: %s", outputName, err.Error())
+	}
+}
+
+// checkSnapshotMissing stops unless no snapshot is named name. A snapshot
+// which cannot be read, because the store is unreachable or the snapshot is
+// corrupt, must not be overwritten.
+func checkSnapshotMissing(name string, config *Config) {
+	_, err := SnapshotLoad(name, config)
+	if err == nil {
+		log.Fatalf("Snapshot %s already exist", name)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("Cannot check whether snapshot %s exists: %s", name, err.Error())
+	}
+}
+
+// snapshotCopy writes snapshot name as newName, deleting the original one
+// when rename is set.
+func snapshotCopy(customConfigPath string, name string, newName string, rename bool) {
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	snapshot, err := SnapshotLoad(name, config)
+	if err != nil {
+		log.Fatalf("Cannot load snapshot %s: %s", name, err.Error())
+	}
+	checkSnapshotMissing(newName, config)
+	snapshot.Name = newName
+	if err = snapshot.Write(); err != nil {
+		log.Fatalf("Cannot write snapshot %s: %s", newName, err.Error())
+	}
+	if !rename {
+		return
+	}
+	snapshot.Name = name
+	if err = snapshot.Delete(); err != nil {
+		log.Fatalf("Cannot delete snapshot %s: %s", name, err.Error())
+	}
+}
+
+// parseForgetFilter builds the filter of objects to forget from --type,
+// pattern and tag options, nil when no option is set.
+func parseForgetFilter(options map[string]string) *ResourceFilterEnvelope {
+	selectors := parseObjectSelectors(options)
+	if len(options["type"]) == 0 {
+		return selectors
+	}
+	return combineFilters(NewResourceFilterOnly(strings.Split(options["type"], ",")), selectors)
+}
+
+func snapshotForget(customConfigPath string, name string, options map[string]string) {
+	var ids []string
+	if len(options["id"]) > 0 {
+		ids = strings.Split(options["id"], ",")
+	}
+	// Exclusions alone would forget every other object of the snapshot.
+	if len(options["type"]) == 0 && len(ids) == 0 && len(options["match"]) == 0 && len(options["tag"]) == 0 {
+		log.Fatal("Select objects to forget with --type, --id, --match or --tag options")
+	}
+	filter := parseForgetFilter(options)
+	var configPath *string
+	if len(customConfigPath) > 0 {
+		configPath = &customConfigPath
+	}
+	config, err := ConfigLoadWithDefault(configPath)
+	if err != nil {
+		log.Fatalf("Cannot load configuration: %s", err.Error())
+	}
+	snapshot, err := SnapshotLoad(name, config)
+	if err != nil {
+		log.Fatalf("Snapshot %s does not exist", name)
+	}
+	profileName := options["profile"]
+	if len(profileName) > 0 && !slices.ContainsFunc(snapshot.Data, func(data SnapshotData) bool { return data.Profile == profileName }) {
+		log.Fatalf("Snapshot %s has no object for profile %s", name, profileName)
+	}
+
+	count := 0
+	for i := range snapshot.Data {
+		data := &snapshot.Data[i]
+		if len(profileName) > 0 && data.Profile != profileName {
+			continue
+		}
+		selected := data.Select(filter, ids)
+		if options["incremental"] == "true" {
+			for _, typeName := range slices.Sorted(maps.Keys(selected)) {
+				chosen, err := incrementalChoice(incrementalForget, typeName, selected[typeName])
+				if err != nil {
+					log.Fatalf("Forget failed: %s", err.Error())
+				}
+				if chosen == nil {
+					log.Fatalf("Forget cancelled, snapshot %s is unchanged", name)
+				}
+				selected[typeName] = *chosen
+			}
+		}
+		for _, typeName := range slices.Sorted(maps.Keys(selected)) {
+			for _, object := range selected[typeName] {
+				log.Printf("Forget %s %s from profile %s (%s)\n", typeName, object.String(), data.Profile, data.Provider)
+			}
+		}
+		count += data.Forget(selected)
+	}
+	if count == 0 {
+		log.Printf("No object to forget in snapshot %s\n", name)
+		return
+	}
+	message := fmt.Sprintf("Do you really want to forget %d objects from snapshot %s?\n", count, name) +
+		"  The next clean will delete all resources shown above."
+	if !confirmAction(&message, options["auto-approve"] == "true") {
+		log.Fatalf("Forget cancelled, snapshot %s is unchanged", name)
+	}
+	if err = snapshot.Write(); err != nil {
+		log.Fatalf("Cannot write snapshot %s: %s", name, err.Error())
+	}
+	log.Printf("%d objects forgotten from snapshot %s, they will be deleted by the next clean\n", count, name)
+}
@@ -2,12 +2,14 @@ package main
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/outscale/frieza/internal/common"
@@ -15,6 +17,15 @@ import (
 
 type Destroyer struct {
 	Targets []DestroyerTarget `json:"targets"`
+	config  *Config
+	// command is the frieza command deleting objects, recorded in the
+	// journal.
+	command   string
+	runId     string
+	startedAt time.Time
+	// appliedPlan is the plan applied, kept in run files so that resume can check
+	// its signatures.
+	appliedPlan *Plan
 }
 
 type DestroyerTarget struct {
@@ -22,6 +33,7 @@ type DestroyerTarget struct {
 	JsonProfile *DestroyerProfile `json:"profile"`
 	provider    *Provider         `json:"-"`
 	Objects     *Objects          `json:"objects"`
+	Skipped     SkippedObjects    `json:"skipped,omitempty"`
 }
 
 type DestroyerProfile struct {
@@ -29,12 +41,57 @@ type DestroyerProfile struct {
 	Provider string `json:"provider"`
 }
 
-func NewDestroyer() *Destroyer {
-	var destroyer Destroyer
-	return &destroyer
+// Delays between deletion rounds grow from minRoundDelay to maxRoundDelay
+// while no object is deleted. Throttled targets wait the same way.
+const (
+	minRoundDelay = time.Second
+	maxRoundDelay = 10 * time.Second
+)
+
+// exitLeaked is the exit code of commands leaving objects which could not be
+// deleted.
+const exitLeaked = 2
+
+type DestroyerReport struct {
+	Failures []DestroyerFailure `json:"failures"`
+	// Run is set when objects are left, to resume the run.
+	Run string `json:"run,omitempty"`
+	// Deleted is the number of objects deleted by the run.
+	Deleted int `json:"deleted"`
+	// Interrupted is set when the run was stopped by a signal.
+	Interrupted bool `json:"interrupted,omitempty"`
+	// Stalled is set when the run stopped as no object could be deleted
+	// anymore.
+	Stalled bool `json:"stalled,omitempty"`
+}
+
+type DestroyerFailure struct {
+	Profile  string          `json:"profile"`
+	Provider string          `json:"provider"`
+	Type     ObjectType      `json:"type"`
+	Object   Object          `json:"object"`
+	Kind     DeleteErrorKind `json:"kind"`
+	Error    string          `json:"error"`
+}
+
+// deleteTracker remembers the last deletion result of each object of a target.
+type deleteTracker struct {
+	last   map[ObjectType]map[string]DeleteResult
+	gaveUp map[ObjectType][]DeleteResult
+}
+
+func NewDestroyer(config *Config, command string) *Destroyer {
+	return &Destroyer{
+		config:    config,
+		command:   command,
+		runId:     NewRunId(),
+		startedAt: time.Now().UTC(),
+	}
 }
 
-func (destroyer *Destroyer) add(profile *Profile, provider *Provider, objectsToDelete *Objects) {
+// add registers objects to delete, except protected ones which join the
+// skipped objects.
+func (destroyer *Destroyer) add(profile *Profile, provider *Provider, objectsToDelete *Objects, skipped SkippedObjects) {
 	target := DestroyerTarget{
 		profile: profile,
 		JsonProfile: &DestroyerProfile{
@@ -43,6 +100,14 @@ func (destroyer *Destroyer) add(profile *Profile, provider *Provider, objectsToD
 		},
 		provider: provider,
 		Objects:  objectsToDelete,
+		Skipped:  skipped,
+	}
+	protected := Protect(destroyer.config.ProtectedRules(profile), objectsToDelete)
+	for typeName, typeProtected := range protected {
+		if target.Skipped == nil {
+			target.Skipped = make(SkippedObjects)
+		}
+		target.Skipped[typeName] = append(target.Skipped[typeName], typeProtected...)
 	}
 	destroyer.Targets = append(destroyer.Targets, target)
 }
@@ -71,12 +136,33 @@ func (destroyer *Destroyer) print_human() {
 		}
 		totalObjectCount += objectsCount
 		log.Print(ObjectsPrint(target.provider, target.Objects))
+		printSkipped(&target)
 	}
 	if totalObjectCount == 0 {
 		log.Println("\nNothing to delete")
 	}
 }
 
+func printSkipped(target *DestroyerTarget) {
+	if len(target.Skipped) == 0 {
+		return
+	}
+	log.Printf(
+		"Objects skipped in profile %s (%s):\n",
+		target.profile.Name,
+		(*target.provider).Name(),
+	)
+	for _, typeName := range (*target.provider).Types() {
+		if len(target.Skipped[typeName]) == 0 {
+			continue
+		}
+		log.Printf("%s:\n", typeName)
+		for _, skipped := range target.Skipped[typeName] {
+			log.Printf("  - %s: %s\n", (*target.provider).StringObject(skipped.Object, typeName), skipped.Rule)
+		}
+	}
+}
+
 func (destroyer *Destroyer) print_json() {
 	json_bytes, err := json.MarshalIndent(destroyer, "", "  ")
 	if err != nil {
@@ -85,57 +171,355 @@ func (destroyer *Destroyer) print_json() {
 	log.Print(string(json_bytes))
 }
 
-func (destroyer *Destroyer) run(ctx context.Context) {
+// run deletes the objects of the targets, from up to parallelism targets at
+// the same time.
+func (destroyer *Destroyer) run(ctx context.Context, parallelism int) *DestroyerReport {
+	parallelism = cmp.Or(parallelism, destroyer.config.DestroyParallelism, DefaultDestroyParallelism)
+	journal, err := OpenJournal(destroyer.config.JournalPath, destroyer.runId, destroyer.command)
+	if err != nil {
+		log.Fatalf("Cannot open journal: %s", err.Error())
+	}
+	defer journal.Close()
+	if err := destroyer.checkpoint(); err != nil {
+		log.Fatalf("Cannot save run: %s", err.Error())
+	}
+	interrupted, stopInterrupt := notifyInterrupt()
+	defer stopInterrupt()
+	var deleted int
 	var objects []*Objects
-	for i := range destroyer.Targets {
+	var graphs []*DependencyGraph
+	var trackers []*deleteTracker
+	// Deletions in a throttled target wait until its notBefore time.
+	var backoffs []*Backoff
+	notBefore := make([]time.Time, len(destroyer.Targets))
+	for i, target := range destroyer.Targets {
+		graph, err := NewDependencyGraph(target.provider)
+		if err != nil {
+			log.Fatalf(
+				"Cannot order deletion in profile %s (%s): %s",
+				target.profile.Name,
+				(*target.provider).Name(),
+				err.Error(),
+			)
+		}
+		graphs = append(graphs, graph)
 		objects = append(objects, destroyer.Targets[i].Objects)
+		trackers = append(trackers, newDeleteTracker())
+		backoffs = append(backoffs, NewBackoff(minRoundDelay, maxRoundDelay))
 	}
-	var hasObjectsLeft []bool
-	for range objects {
-		hasObjectsLeft = append(hasObjectsLeft, true)
+	// stop reports the objects left, the run can be resumed.
+	stop := func() *DestroyerReport {
+		destroyer.printBlocked(objects, graphs)
+		report := destroyer.report(objects, graphs, trackers)
+		report.Deleted = deleted
+		report.Run = destroyer.runId
+		return report
 	}
+	stallRounds := cmp.Or(destroyer.config.StallRounds, DefaultStallRounds)
+	lastObjectCount := -1
+	stalledRounds := 0
+	throttled := false
+	roundBackoff := NewBackoff(minRoundDelay, maxRoundDelay)
 	for {
-		var objectsCount []int
-		var totalObjectCount int
-		for i := range objects {
-			objectsCount = append(objectsCount, 0)
-			if !hasObjectsLeft[i] {
-				continue
-			}
-			count := ObjectsCount(objects[i])
-			objectsCount[i] = count
-			if count == 0 {
-				hasObjectsLeft[i] = false
-				continue
+		totalObjectCount := countObjects(objects)
+		if totalObjectCount == 0 {
+			if err := destroyer.finish(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing run: %v\n", err)
 			}
-			totalObjectCount += count
+			report := destroyer.report(objects, graphs, trackers)
+			report.Deleted = deleted
+			return report
 		}
-		if totalObjectCount == 0 {
-			return
+		// Rounds slowed down by throttling do not count as stalled.
+		if lastObjectCount < 0 || totalObjectCount < lastObjectCount {
+			stalledRounds = 0
+			roundBackoff.Reset()
+		} else if !throttled {
+			stalledRounds++
+		}
+		throttled = false
+		lastObjectCount = totalObjectCount
+		if stalledRounds >= stallRounds {
+			log.Printf("No object deleted during %d rounds, giving up\n", stalledRounds)
+			report := stop()
+			report.Stalled = true
+			return report
 		}
+		// Targets are deleted from at the same time, provider messages are
+		// then prefixed with their target.
+		concurrent := parallelism > 1 && len(destroyer.Targets) > 1
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		workers := make(chan struct{}, parallelism)
 		for i, target := range destroyer.Targets {
-			if !hasObjectsLeft[i] {
+			// Only delete types whose dependencies are already gone, the
+			// others would fail anyway.
+			wave := make(Objects)
+			for _, typeName := range graphs[i].Ready(*objects[i]) {
+				wave[typeName] = (*objects[i])[typeName]
+			}
+			if len(wave) == 0 {
 				continue
 			}
-			DeleteObjects(ctx, target.provider, *objects[i])
-			time.Sleep(100 * time.Millisecond)
+			wg.Go(func() {
+				workers <- struct{}{}
+				defer func() { <-workers }()
+				// Other targets are not started once interrupted.
+				if isInterrupted(interrupted) {
+					return
+				}
+				if time.Now().Before(notBefore[i]) {
+					mutex.Lock()
+					throttled = true
+					mutex.Unlock()
+					return
+				}
+				ctx := WithProtectRules(ctx, destroyer.config.ProtectedRules(target.profile))
+				if concurrent {
+					ctx = WithLogger(ctx, target.logger())
+				}
+				if err := journal.RecordAttempts(target.profile.Name, (*target.provider).Name(), wave); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing journal: %v\n", err)
+				}
+				results := DeleteObjects(ctx, target.provider, wave)
+				if err := journal.Record(target.profile.Name, (*target.provider).Name(), results); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing journal: %v\n", err)
+				}
+				trackers[i].record(results)
+				mutex.Lock()
+				for _, typeResults := range results {
+					for _, result := range typeResults {
+						if result.Deleted() {
+							deleted++
+						}
+					}
+				}
+				if Throttled(results) {
+					throttled = true
+				}
+				mutex.Unlock()
+				if Throttled(results) {
+					delay := backoffs[i].Next()
+					notBefore[i] = time.Now().Add(delay)
+					log.Printf("Profile %s (%s) is throttled, slowing down for %s\n",
+						target.profile.Name, (*target.provider).Name(), delay.Round(time.Millisecond))
+				} else {
+					backoffs[i].Reset()
+				}
+				remaining, err := ReadRemainingObjects(ctx, target.provider, wave)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading: %v\n", err)
+				}
+				for typeName, typeObjects := range remaining {
+					(*objects[i])[typeName] = typeObjects
+				}
+				trackers[i].prune(objects[i])
+			})
 		}
-		for i, target := range destroyer.Targets {
-			diff := NewDiff()
-			remaining, err := ReadNonEmptyObjects(ctx, target.provider, *objects[i])
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading: %v\n", err)
-			}
-			diff.Build(&remaining, objects[i])
-			objects[i] = &diff.Retained
+		wg.Wait()
+		if err := destroyer.checkpoint(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving run: %v\n", err)
+		}
+		// A run whose last objects were deleted during this round finishes,
+		// even if it was interrupted meanwhile.
+		if countObjects(objects) == 0 {
+			continue
 		}
 
 		select {
 		case <-ctx.Done():
 			log.Printf("Operation cancelled: %v\n", ctx.Err())
-			return
-		case <-time.After(time.Second):
+			return stop()
+		case <-interrupted:
+			log.Printf("Operation interrupted, %d objects deleted\n", deleted)
+			report := stop()
+			report.Interrupted = true
+			return report
+		case <-time.After(roundDelay(roundBackoff, throttled, notBefore)):
+		}
+	}
+}
+
+func countObjects(objects []*Objects) int {
+	count := 0
+	for i := range objects {
+		count += ObjectsCount(objects[i])
+	}
+	return count
+}
+
+// roundDelay is the time to wait before the next deletion round: until the
+// first throttled target can be retried, or a growing delay otherwise.
+func roundDelay(backoff *Backoff, throttled bool, notBefore []time.Time) time.Duration {
+	if !throttled {
+		return backoff.Next()
+	}
+	var next time.Time
+	for _, at := range notBefore {
+		if at.After(time.Now()) && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+	return max(time.Until(next), 0)
+}
+
+// logger prefixes messages with the target name, for messages of targets
+// deleted from at the same time.
+func (target *DestroyerTarget) logger() *log.Logger {
+	prefix := fmt.Sprintf("[%s (%s)] ", target.profile.Name, (*target.provider).Name())
+	return log.New(log.Writer(), prefix, log.Flags())
+}
+
+func (destroyer *Destroyer) printBlocked(objects []*Objects, graphs []*DependencyGraph) {
+	for i, target := range destroyer.Targets {
+		if ObjectsCount(objects[i]) == 0 {
+			continue
+		}
+		log.Printf(
+			"Objects left in profile %s (%s):\n",
+			target.profile.Name,
+			(*target.provider).Name(),
+		)
+		for _, typeName := range (*target.provider).Types() {
+			count := len((*objects[i])[typeName])
+			if count == 0 {
+				continue
+			}
+			blockers := graphs[i].BlockedBy(typeName, *objects[i])
+			if len(blockers) == 0 {
+				log.Printf("  - %s: %d\n", typeName, count)
+			} else {
+				log.Printf("  - %s: %d (waiting for %s)\n", typeName, count, strings.Join(blockers, ", "))
+			}
+		}
+	}
+}
+
+// report lists objects which could not be deleted, with the reason of the
+// last failure.
+func (destroyer *Destroyer) report(objects []*Objects, graphs []*DependencyGraph, trackers []*deleteTracker) *DestroyerReport {
+	report := DestroyerReport{Failures: []DestroyerFailure{}}
+	for i, target := range destroyer.Targets {
+		addFailure := func(typeName ObjectType, object Object, kind DeleteErrorKind, err string) {
+			report.Failures = append(report.Failures, DestroyerFailure{
+				Profile:  target.profile.Name,
+				Provider: (*target.provider).Name(),
+				Type:     typeName,
+				Object:   object,
+				Kind:     kind,
+				Error:    err,
+			})
+		}
+		for _, typeName := range (*target.provider).Types() {
+			for _, result := range trackers[i].gaveUp[typeName] {
+				addFailure(typeName, result.Object, result.Kind, result.Err.Error())
+			}
+			for _, object := range (*objects[i])[typeName] {
+				result, found := trackers[i].last[typeName][object.Id]
+				switch {
+				case found && result.Err != nil:
+					addFailure(typeName, object, result.Kind, result.Err.Error())
+				case found:
+					addFailure(typeName, object, DeleteErrorRetryable, "object still exists")
+				default:
+					blockers := graphs[i].BlockedBy(typeName, *objects[i])
+					if len(blockers) == 0 {
+						addFailure(typeName, object, DeleteErrorRetryable, "not deleted yet")
+						continue
+					}
+					addFailure(typeName, object, DeleteErrorDependency,
+						fmt.Sprintf("waiting for %s", strings.Join(blockers, ", ")))
+				}
+			}
+		}
+	}
+	return &report
+}
+
+// exitCode is exitInterrupted when the run was interrupted, exitLeaked when
+// objects could not be deleted and 0 otherwise.
+func (report *DestroyerReport) exitCode() int {
+	switch {
+	case report.Interrupted:
+		return exitInterrupted
+	case len(report.Failures) > 0:
+		return exitLeaked
+	default:
+		return 0
+	}
+}
+
+func (report *DestroyerReport) print(json bool) {
+	if json {
+		report.print_json()
+	} else {
+		report.print_human()
+	}
+}
+
+func (report *DestroyerReport) print_human() {
+	if len(report.Failures) > 0 {
+		log.Printf("\nFailed to delete %d objects:\n", len(report.Failures))
+	}
+	for _, failure := range report.Failures {
+		log.Printf(
+			"  - %s (%s) %s %s: [%s] %s\n",
+			failure.Profile,
+			failure.Provider,
+			failure.Type,
+			failure.Object,
+			failure.Kind,
+			failure.Error,
+		)
+	}
+	if len(report.Run) > 0 {
+		log.Printf("\nRun %s stopped, continue it with: frieza resume %s\n", report.Run, report.Run)
+	}
+}
+
+// print_json writes on stdout as logs are disabled once deletion started.
+func (report *DestroyerReport) print_json() {
+	json_bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		cliFatalf(true, "Cannot serialize to json: %s", err.Error())
+	}
+	fmt.Println(string(json_bytes))
+}
+
+func newDeleteTracker() *deleteTracker {
+	return &deleteTracker{
+		last:   make(map[ObjectType]map[string]DeleteResult),
+		gaveUp: make(map[ObjectType][]DeleteResult),
+	}
+}
+
+func (tracker *deleteTracker) record(results DeleteResults) {
+	for typeName, typeResults := range results {
+		if tracker.last[typeName] == nil {
+			tracker.last[typeName] = make(map[string]DeleteResult)
+		}
+		for _, result := range typeResults {
+			tracker.last[typeName][result.Object.Id] = result
+		}
+	}
+}
+
+// prune removes objects which must not be deleted again: those already gone
+// and those whose deletion is refused.
+func (tracker *deleteTracker) prune(objects *Objects) {
+	for typeName, typeObjects := range *objects {
+		kept := make([]Object, 0, len(typeObjects))
+		for _, object := range typeObjects {
+			result, found := tracker.last[typeName][object.Id]
+			switch {
+			case !found, result.Err == nil, result.Retryable():
+				kept = append(kept, object)
+			case result.Kind == DeleteErrorForbidden:
+				tracker.gaveUp[typeName] = append(tracker.gaveUp[typeName], result)
+				delete(tracker.last[typeName], object.Id)
+			}
 		}
+		(*objects)[typeName] = kept
 	}
 }
 
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+// orderedProvider deletes security groups after vms.
+type orderedProvider struct{}
+
+func (provider orderedProvider) Name() string                       { return "ordered" }
+func (provider orderedProvider) Types() []ObjectType                { return []ObjectType{"vm", "security_group"} }
+func (provider orderedProvider) AuthTest(ctx context.Context) error { return nil }
+func (provider orderedProvider) StringObject(object Object, typeName string) string {
+	return object.Id
+}
+
+func (provider orderedProvider) ReadObjects(ctx context.Context, typeName string) ([]Object, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (provider orderedProvider) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
+	return nil
+}
+
+func (provider orderedProvider) Dependencies() map[ObjectType][]ObjectType {
+	return map[ObjectType][]ObjectType{"security_group": {"vm"}}
+}
+
+func TestPruneForbiddenDependency(t *testing.T) {
+	var provider Provider = orderedProvider{}
+	graph, err := NewDependencyGraph(&provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forbidden, throttled := NewObject("i-1"), NewObject("i-2")
+	objects := Objects{
+		"vm":             {forbidden, throttled},
+		"security_group": {NewObject("sg-1")},
+	}
+	tracker := newDeleteTracker()
+	tracker.record(DeleteResults{"vm": {
+		DeleteFailed(forbidden, DeleteErrorForbidden, errors.New("protected")),
+		DeleteFailed(throttled, DeleteErrorThrottled, errors.New("slow down")),
+	}})
+	tracker.prune(&objects)
+	if got := graph.BlockedBy("security_group", objects); !slices.Equal(got, []ObjectType{"vm"}) {
+		t.Errorf("security_group blocked by %v, expected the retried vm", got)
+	}
+
+	tracker.record(DeleteResults{"vm": {DeleteFailed(throttled, DeleteErrorForbidden, errors.New("protected"))}})
+	tracker.prune(&objects)
+	if len(objects["vm"]) != 0 || len(tracker.gaveUp["vm"]) != 2 {
+		t.Fatalf("forbidden vms not given up: %v remaining, %v given up", objects["vm"], tracker.gaveUp["vm"])
+	}
+	if got := graph.Ready(objects); !slices.Equal(got, []ObjectType{"security_group"}) {
+		t.Errorf("ready types are %v, forbidden vms must not block security_group", got)
+	}
+}
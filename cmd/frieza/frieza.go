@@ -8,5 +8,5 @@ import (
 func main() {
 	log.SetFlags(0)
 	app := cliRoot()
-	os.Exit(app.Run(os.Args, os.Stdout))
+	os.Exit(app.Run(joinRepeatedOptions(os.Args), os.Stdout))
 }
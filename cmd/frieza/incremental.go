@@ -26,14 +26,25 @@ const (
 	DebugColor   = "\033[0;32m%s\033[0m"
 )
 
-func printIncrementalUsage() {
-	log.Printf(fmt.Sprintf(ErrorColor+"\n", "%v - %v"), ADD_RESOURCE, "add this resource")
+// incrementalAction describes what choosing a resource does to a snapshot.
+type incrementalAction struct {
+	verb   string
+	marker string
+}
+
+var (
+	incrementalAdd    = incrementalAction{verb: "add", marker: "+"}
+	incrementalForget = incrementalAction{verb: "forget", marker: "-"}
+)
+
+func printIncrementalUsage(action incrementalAction) {
+	log.Printf(fmt.Sprintf(ErrorColor+"\n", "%v - %v"), ADD_RESOURCE, action.verb+" this resource")
 	log.Printf(fmt.Sprintf(ErrorColor+"\n", "%v - %v"), SKIP_RESOURCE, "skip the resource")
-	log.Printf(fmt.Sprintf(ErrorColor+"\n", "%v - %v"), CANCEL, "cancel addition of resources")
+	log.Printf(fmt.Sprintf(ErrorColor+"\n", "%v - %v"), CANCEL, "cancel, leaving the snapshot unchanged")
 	log.Printf(
 		fmt.Sprintf(ErrorColor+"\n", "%v - %v"),
 		ADD_TYPE,
-		"add this resource and all later resources of this type",
+		action.verb+" this resource and all later resources of this type",
 	)
 	log.Printf(
 		fmt.Sprintf(ErrorColor+"\n", "%v - %v"),
@@ -43,14 +54,15 @@ func printIncrementalUsage() {
 	log.Printf(fmt.Sprintf(ErrorColor+"\n", "%v - %v"), HELP, "print help")
 }
 
-func retrieveNextUserInput(message string, currentStage int, numberStage int) string {
+func retrieveNextUserInput(action incrementalAction, message string, currentStage int, numberStage int) string {
 	acceptedChar := []string{ADD_RESOURCE, SKIP_RESOURCE, CANCEL, ADD_TYPE, SKIP_TYPE, HELP}
 	for {
 		log.Printf("%v\n", message)
 		log.Printf(
-			fmt.Sprintf(InfoColor, "(%d/%d) Add this resource [%v]? "),
+			fmt.Sprintf(InfoColor, "(%d/%d) %s this resource [%v]? "),
 			currentStage,
 			numberStage,
+			strings.ToUpper(action.verb[:1])+action.verb[1:],
 			strings.Join(acceptedChar, ","),
 		)
 
@@ -62,9 +74,9 @@ func retrieveNextUserInput(message string, currentStage int, numberStage int) st
 		case ADD_RESOURCE, SKIP_RESOURCE, CANCEL, ADD_TYPE, SKIP_TYPE:
 			return response
 		case HELP:
-			printIncrementalUsage()
+			printIncrementalUsage(action)
 		default:
-			printIncrementalUsage()
+			printIncrementalUsage(action)
 		}
 	}
 }
@@ -77,12 +89,12 @@ a - stage this hunk and all later hunks in the type
 d - do not stage this hunk or any of the later hunks in the type
 ? - print help
 */
-func incrementalChoice(typeName ObjectType, values []Object) (*[]Object, error) {
+func incrementalChoice(action incrementalAction, typeName ObjectType, values []Object) (*[]Object, error) {
 	selectObjects := []Object{}
-	templateMessage := fmt.Sprintf(InfoColor+"\n"+DebugColor+"\n", "# Type : %v", "+ %v")
+	templateMessage := fmt.Sprintf(InfoColor+"\n"+DebugColor+"\n", "# Type : %v", action.marker+" %v")
 	numberStage := len(values)
 	for i, value := range values {
-		switch retrieveNextUserInput(fmt.Sprintf(templateMessage, typeName, value), i+1, numberStage) {
+		switch retrieveNextUserInput(action, fmt.Sprintf(templateMessage, typeName, value), i+1, numberStage) {
 		case ADD_RESOURCE:
 			selectObjects = append(selectObjects, value)
 			continue
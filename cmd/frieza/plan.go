@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+// PlanVersion is the version of saved plans written by this frieza.
+const PlanVersion = 1
+
+// DefaultPlanTTL is how long a saved plan can be applied.
+const DefaultPlanTTL = time.Hour
+
+// Plan is a deletion plan saved by clean or nuke, to be reviewed and then
+// executed by apply.
+type Plan struct {
+	Version   int       `json:"version"`
+	Command   string    `json:"command"`
+	Snapshot  string    `json:"snapshot,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Filters selected the planned objects, apply checks they still do.
+	Filters *ResourceFilterEnvelope `json:"filters,omitempty"`
+	Targets []DestroyerTarget       `json:"targets"`
+	// Signatures cover all other fields of the plan.
+	Signatures []Signature `json:"signatures,omitempty"`
+}
+
+// PlanMismatch is a planned object which cannot be deleted as planned.
+type PlanMismatch struct {
+	Profile  string
+	Provider string
+	Type     ObjectType
+	Object   Object
+	Reason   string
+}
+
+func (mismatch PlanMismatch) String() string {
+	return fmt.Sprintf("%s (%s) %s %s: %s", mismatch.Profile, mismatch.Provider, mismatch.Type, mismatch.Object, mismatch.Reason)
+}
+
+func (destroyer *Destroyer) plan(command string, snapshot string, filters *ResourceFilterEnvelope) *Plan {
+	return &Plan{
+		Version:   PlanVersion,
+		Command:   command,
+		Snapshot:  snapshot,
+		CreatedAt: time.Now().UTC(),
+		Filters:   filters,
+		Targets:   destroyer.Targets,
+	}
+}
+
+// savePlan writes the plan of destroyer for a later apply, objects having
+// been selected with filters.
+func (destroyer *Destroyer) savePlan(path string, command string, snapshot string, filters *ResourceFilterEnvelope, jsonOutput bool) {
+	if err := destroyer.plan(command, snapshot, filters).Write(path); err != nil {
+		cliFatalf(jsonOutput, "Cannot save plan: %s", err.Error())
+	}
+	if !jsonOutput {
+		log.Printf("\nPlan saved, delete its resources with: frieza apply %s\n", path)
+	}
+}
+
+// Write saves the plan, only readable by its owner as it lists resources.
+func (plan *Plan) Write(path string) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func PlanLoad(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plan Plan
+	if err = json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	if plan.Version != PlanVersion {
+		return nil, fmt.Errorf("unsupported plan version %d", plan.Version)
+	}
+	if err = validateTargets(plan.Targets); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// payload is what signatures cover: the plan without its signatures.
+func (plan *Plan) payload() ([]byte, error) {
+	unsigned := *plan
+	unsigned.Signatures = nil
+	return json.Marshal(unsigned)
+}
+
+// Sign adds the signature of key, replacing a previous one of the same key.
+func (plan *Plan) Sign(key ed25519.PrivateKey) error {
+	payload, err := plan.payload()
+	if err != nil {
+		return err
+	}
+	signature := Sign(payload, key)
+	plan.Signatures = slices.DeleteFunc(plan.Signatures, func(other Signature) bool {
+		return other.PublicKey == signature.PublicKey
+	})
+	plan.Signatures = append(plan.Signatures, signature)
+	return nil
+}
+
+// Signers returns the names of the trusted keys having signed the plan.
+func (plan *Plan) Signers(config *Config) ([]string, error) {
+	payload, err := plan.payload()
+	if err != nil {
+		return nil, err
+	}
+	return config.VerifySignatures(payload, plan.Signatures)
+}
+
+// Age is the time elapsed since the plan was made.
+func (plan *Plan) Age() time.Duration {
+	return time.Since(plan.CreatedAt)
+}
+
+func validateTargets(targets []DestroyerTarget) error {
+	for _, target := range targets {
+		if target.JsonProfile == nil || target.Objects == nil {
+			return errors.New("target without profile or objects")
+		}
+	}
+	return nil
+}
+
+// destroyer reads the planned objects again and builds a destroyer deleting
+// the ones still matching the plan. Live objects replace planned ones, so that
+// protect rules and the filters of the plan apply to their current name and
+// tags. The others are returned as mismatches.
+func (plan *Plan) destroyer(ctx context.Context, config *Config, inventory *Inventory) (*Destroyer, []PlanMismatch, error) {
+	var profiles []*Profile
+	var providers []*Provider
+	var targets []InventoryTarget
+	for _, target := range plan.Targets {
+		profile, provider, err := targetProvider(config, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		var types []ObjectType
+		for _, typeName := range (*provider).Types() {
+			if len((*target.Objects)[typeName]) > 0 {
+				types = append(types, typeName)
+			}
+		}
+		filters := NewResourceFilterOnly(types)
+		if plan.Filters != nil {
+			filters = filters.With(plan.Filters)
+		}
+		profiles = append(profiles, profile)
+		providers = append(providers, provider)
+		targets = append(targets, newProfileTarget(profile, provider, filters))
+	}
+	current, err := inventory.Collect(ctx, targets)
+	if err != nil {
+		return nil, nil, err
+	}
+	destroyer := NewDestroyer(config, "apply")
+	var mismatches []PlanMismatch
+	for i, target := range plan.Targets {
+		addMismatch := func(typeName ObjectType, object Object, reason string) {
+			mismatches = append(mismatches, PlanMismatch{
+				Profile:  profiles[i].Name,
+				Provider: (*providers[i]).Name(),
+				Type:     typeName,
+				Object:   object,
+				Reason:   reason,
+			})
+		}
+		live := make(Objects)
+		for typeName, objects := range *target.Objects {
+			if !slices.Contains((*providers[i]).Types(), typeName) {
+				for _, object := range objects {
+					addMismatch(typeName, object, "unknown type")
+				}
+				continue
+			}
+			currentObjects := make(map[string]Object)
+			for _, object := range current[i].Objects[typeName] {
+				currentObjects[object.Id] = object
+			}
+			skippedRules := make(map[string]string)
+			for _, skipped := range current[i].Skipped[typeName] {
+				skippedRules[skipped.Object.Id] = skipped.Rule
+			}
+			for _, object := range objects {
+				liveObject, found := currentObjects[object.Id]
+				rule, skipped := skippedRules[object.Id]
+				switch {
+				case skipped:
+					addMismatch(typeName, object, rule)
+				case !found:
+					addMismatch(typeName, object, "no longer exists")
+				case liveObject.Name != object.Name || !maps.Equal(liveObject.Tags, object.Tags):
+					addMismatch(typeName, liveObject, "name or tags changed since planning")
+				default:
+					live[typeName] = append(live[typeName], liveObject)
+				}
+			}
+		}
+		destroyer.add(profiles[i], providers[i], &live, nil)
+		for typeName, protected := range destroyer.Targets[i].Skipped {
+			for _, object := range protected {
+				addMismatch(typeName, object.Object, object.Rule)
+			}
+		}
+	}
+	return destroyer, mismatches, nil
+}
+
+// newTargetsDestroyer builds a destroyer deleting the objects of saved
+// targets with the providers of their profile. Protection rules are applied
+// again.
+func newTargetsDestroyer(config *Config, command string, targets []DestroyerTarget) (*Destroyer, error) {
+	destroyer := NewDestroyer(config, command)
+	for _, target := range targets {
+		profile, provider, err := targetProvider(config, target)
+		if err != nil {
+			return nil, err
+		}
+		destroyer.add(profile, provider, target.Objects, nil)
+	}
+	return destroyer, nil
+}
+
+// targetProvider returns the profile and provider of a saved target.
+func targetProvider(config *Config, target DestroyerTarget) (*Profile, *Provider, error) {
+	profile, err := config.GetProfile(target.JsonProfile.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot get profile %s: %w", target.JsonProfile.Name, err)
+	}
+	providers, err := ProviderNew(*profile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot initialize profile %s: %w", profile.Name, err)
+	}
+	idx := slices.IndexFunc(providers, func(p Provider) bool {
+		return p.Name() == target.JsonProfile.Provider
+	})
+	if idx == -1 {
+		return nil, nil, fmt.Errorf("profile %s has no provider %s", profile.Name, target.JsonProfile.Provider)
+	}
+	return profile, &providers[idx], nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+func newSignedPlan(t *testing.T) (*Plan, *Config) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{
+		RequiredSignatures: 1,
+		TrustedKeys:        []TrustedKey{{Name: "alice", PublicKey: EncodePublicKey(publicKey)}},
+	}
+	pattern, err := ParseObjectPattern("vm:name~web-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Now().Add(-time.Hour)
+	object := Object{
+		Id:         "i-1",
+		Name:       "web-1",
+		Tags:       map[string]string{"env": "test"},
+		CreatedAt:  &createdAt,
+		Attributes: map[string]string{"state": "running"},
+	}
+	plan := &Plan{
+		Version:   PlanVersion,
+		Command:   "nuke",
+		CreatedAt: time.Now(),
+		Filters: NewResourceFilterAge(time.Hour).
+			WithTags(map[string]string{"env": "test"}, nil).
+			WithPatterns([]ObjectPattern{pattern}, nil),
+		Targets: []DestroyerTarget{{
+			JsonProfile: &DestroyerProfile{Name: "p1", Provider: "outscale_oapi"},
+			Objects:     &Objects{"vm": {object, NewObject("i-2")}},
+		}},
+	}
+	if err = plan.Sign(privateKey); err != nil {
+		t.Fatal(err)
+	}
+	return plan, config
+}
+
+func TestPlanSignaturesSurviveWrite(t *testing.T) {
+	plan, config := newSignedPlan(t)
+	planPath := path.Join(t.TempDir(), "plan.json")
+	if err := plan.Write(planPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := PlanLoad(planPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers, err := loaded.Signers(config)
+	if err != nil || len(signers) != 1 {
+		t.Fatalf("signature of a saved plan not verified: %v, %v", signers, err)
+	}
+
+	(*loaded.Targets[0].Objects)["vm"] = append((*loaded.Targets[0].Objects)["vm"], NewObject("i-3"))
+	if _, err = loaded.Signers(config); err == nil {
+		t.Error("signature accepted on a plan with an added object")
+	}
+}
+
+func TestRunCheckSigned(t *testing.T) {
+	plan, config := newSignedPlan(t)
+	remaining := Objects{"vm": {NewObject("i-2")}}
+	run := Run{
+		Version: RunVersion,
+		Targets: []DestroyerTarget{{JsonProfile: plan.Targets[0].JsonProfile, Objects: &remaining}},
+		Plan:    plan,
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Run
+	if err = json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if err = loaded.checkSigned(config); err != nil {
+		t.Errorf("run of a signed plan rejected: %s", err)
+	}
+
+	remaining["vm"] = append(remaining["vm"], NewObject("i-3"))
+	if err = run.checkSigned(config); err == nil {
+		t.Error("run with an unplanned object accepted")
+	}
+
+	config.RequiredSignatures = 2
+	remaining["vm"] = remaining["vm"][:1]
+	if err = run.checkSigned(config); err == nil {
+		t.Error("run of a plan missing signatures accepted")
+	}
+
+	run.Plan = nil
+	if err = run.checkSigned(config); err == nil {
+		t.Error("run without plan accepted")
+	}
+}
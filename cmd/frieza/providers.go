@@ -4,12 +4,18 @@ import (
 	"fmt"
 
 	. "github.com/outscale/frieza/internal/common"
-	"github.com/outscale/frieza/internal/providers/fs"
-	oapi "github.com/outscale/frieza/internal/providers/outscale_oapi"
-	oks "github.com/outscale/frieza/internal/providers/outscale_oks"
-	oos "github.com/outscale/frieza/internal/providers/outscale_oos"
-	"github.com/outscale/frieza/internal/providers/s3"
 	"github.com/teris-io/cli"
+
+	// Providers register themselves, import a package to make it available.
+	_ "github.com/outscale/frieza/internal/providers/fs"
+	_ "github.com/outscale/frieza/internal/providers/outscale_oapi"
+	_ "github.com/outscale/frieza/internal/providers/outscale_oks"
+	_ "github.com/outscale/frieza/internal/providers/outscale_oos"
+	_ "github.com/outscale/frieza/internal/providers/plugin"
+	_ "github.com/outscale/frieza/internal/providers/s3"
+
+	// Snapshot stores other than local folders register themselves too.
+	_ "github.com/outscale/frieza/internal/stores/s3store"
 )
 
 func ProviderNew(profile Profile) ([]Provider, error) {
@@ -19,22 +25,7 @@ func ProviderNew(profile Profile) ([]Provider, error) {
 		return nil, err
 	}
 	for _, providerName := range providerNames {
-		var provider Provider
-		var err error
-		switch providerName {
-		case oapi.Name:
-			provider, err = oapi.New(profile.Config, GlobalCliOptions.debug)
-		case s3.Name:
-			provider, err = s3.New(profile.Config, GlobalCliOptions.debug)
-		case fs.Name:
-			provider, err = fs.New(profile.Config, GlobalCliOptions.debug)
-		case oks.Name:
-			provider, err = oks.New(profile.Config, GlobalCliOptions.debug)
-		case oos.Name:
-			provider, err = oos.New(profile.Config, GlobalCliOptions.debug)
-		default:
-			return nil, fmt.Errorf("provider %s not found", providerName)
-		}
+		provider, err := NewProvider(providerName, profile.Config, GlobalCliOptions.debug)
 		if err != nil {
 			return nil, err
 		}
@@ -46,18 +37,11 @@ func ProviderNew(profile Profile) ([]Provider, error) {
 	return providers, nil
 }
 
-var providersCli = []func() (string, cli.Command){
-	oapi.Cli,
-	s3.Cli,
-	fs.Cli,
-	oks.Cli,
-	oos.Cli,
-}
-
-var providersTypes = map[string][]ObjectType{
-	oapi.Name: oapi.Types(),
-	oks.Name:  oks.Types(),
-	s3.Name:   s3.Types(),
-	fs.Name:   fs.Types(),
-	oos.Name:  oos.Types(),
+// providerCli builds the profile creation command of a registered provider.
+func providerCli(registration ProviderRegistration) cli.Command {
+	cmd := cli.NewCommand(registration.Name, "create new "+registration.Description+" profile")
+	for _, option := range registration.Config {
+		cmd = cmd.WithOption(cli.NewOption(option.Name, option.Description))
+	}
+	return cmd
 }
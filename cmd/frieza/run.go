@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+// RunVersion is the version of run files written by this frieza.
+const RunVersion = 1
+
+// Run is the checkpoint of a clean, nuke or apply run: the objects it still
+// has to delete. It is kept until all objects are deleted, so that an
+// interrupted run can be resumed.
+type Run struct {
+	Version   int               `json:"version"`
+	Id        string            `json:"id"`
+	Command   string            `json:"command"`
+	StartedAt time.Time         `json:"started_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Targets   []DestroyerTarget `json:"targets"`
+	// Plan is the plan applied by the run, with its signatures.
+	Plan *Plan `json:"plan,omitempty"`
+}
+
+func runPath(config *Config, id string) string {
+	return path.Join(config.RunFolderPath, id+".json")
+}
+
+// checkpoint saves the objects the destroyer still has to delete.
+func (destroyer *Destroyer) checkpoint() error {
+	run := Run{
+		Version:   RunVersion,
+		Id:        destroyer.runId,
+		Command:   destroyer.command,
+		StartedAt: destroyer.startedAt,
+		UpdatedAt: time.Now().UTC(),
+		Targets:   destroyer.Targets,
+		Plan:      destroyer.appliedPlan,
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(destroyer.config.RunFolderPath, os.ModePerm); err != nil {
+		return err
+	}
+	// Writing a temporary file first keeps the previous checkpoint if frieza
+	// is killed while writing.
+	runFilePath := runPath(destroyer.config, destroyer.runId)
+	if err := os.WriteFile(runFilePath+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(runFilePath+".tmp", runFilePath)
+}
+
+// finish removes the run file once nothing is left to delete.
+func (destroyer *Destroyer) finish() error {
+	err := os.Remove(runPath(destroyer.config, destroyer.runId))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func RunLoad(config *Config, id string) (*Run, error) {
+	data, err := os.ReadFile(runPath(config, id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("run %s not found or already finished", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var run Run
+	if err = json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("invalid run: %w", err)
+	}
+	if run.Version != RunVersion {
+		return nil, fmt.Errorf("unsupported run version %d", run.Version)
+	}
+	if err = validateTargets(run.Targets); err != nil {
+		return nil, fmt.Errorf("invalid run: %w", err)
+	}
+	return &run, nil
+}
+
+// RunList returns the identifiers of runs which can be resumed, oldest first.
+func RunList(config *Config) ([]string, error) {
+	files, err := os.ReadDir(config.RunFolderPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(file.Name(), ".json"))
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// destroyer builds a destroyer continuing the run.
+func (run *Run) destroyer(config *Config) (*Destroyer, error) {
+	destroyer, err := newTargetsDestroyer(config, run.Command, run.Targets)
+	if err != nil {
+		return nil, err
+	}
+	destroyer.runId = run.Id
+	destroyer.startedAt = run.StartedAt
+	destroyer.appliedPlan = run.Plan
+	return destroyer, nil
+}
+
+// checkSigned returns an error unless the run applies a plan signed by
+// enough trusted keys and only has planned objects left.
+func (run *Run) checkSigned(config *Config) error {
+	if run.Plan == nil {
+		return errors.New("it does not apply a signed plan")
+	}
+	signers, err := run.Plan.Signers(config)
+	if err != nil {
+		return err
+	}
+	if len(signers) < config.RequiredSignatures {
+		return fmt.Errorf("its plan is signed by %d trusted keys, %d are required", len(signers), config.RequiredSignatures)
+	}
+	for _, target := range run.Targets {
+		index := slices.IndexFunc(run.Plan.Targets, func(planned DestroyerTarget) bool {
+			return planned.JsonProfile.Name == target.JsonProfile.Name && planned.JsonProfile.Provider == target.JsonProfile.Provider
+		})
+		if index == -1 {
+			return fmt.Errorf("profile %s (%s) is not in its plan", target.JsonProfile.Name, target.JsonProfile.Provider)
+		}
+		planned := NewObjectIdSets(*run.Plan.Targets[index].Objects)
+		for typeName, objects := range *target.Objects {
+			for _, object := range objects {
+				if _, found := planned[typeName][object.Id]; !found {
+					return fmt.Errorf("%s %s is not in its plan", typeName, object)
+				}
+			}
+		}
+	}
+	return nil
+}
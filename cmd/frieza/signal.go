@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exitInterrupted is the exit code of commands stopped by SIGINT or SIGTERM.
+const exitInterrupted = 130
+
+// notifyInterrupt returns a channel closed on the first SIGINT or SIGTERM, so
+// that deletions in progress can finish. A second signal exits immediately.
+// stop restores the default behavior.
+func notifyInterrupt() (interrupted <-chan struct{}, stop func()) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	closed := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		// Logs may be disabled for json output, the user must still know.
+		fmt.Fprintln(os.Stderr, "\nInterrupted, waiting for deletions in progress (interrupt again to exit now)")
+		close(closed)
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "Exiting without waiting for deletions in progress")
+			os.Exit(exitInterrupted)
+		case <-done:
+		}
+	}()
+	return closed, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+func isInterrupted(interrupted <-chan struct{}) bool {
+	select {
+	case <-interrupted:
+		return true
+	default:
+		return false
+	}
+}
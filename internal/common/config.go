@@ -31,6 +31,29 @@ type Config struct {
 	Version            int       `json:"version"`
 	Profiles           []Profile `json:"profiles"`
 	SnapshotFolderPath string    `json:"snapshot_folder_path,omitempty"`
+	// SnapshotStore is where snapshots are kept, SnapshotFolderPath when
+	// empty or "local".
+	SnapshotStore string `json:"snapshot_store,omitempty"`
+	// SnapshotEncoding is how new snapshots are written, SnapshotEncodingJson
+	// when empty.
+	SnapshotEncoding string `json:"snapshot_encoding,omitempty"`
+	Parallelism      int    `json:"parallelism,omitempty"`
+	// DestroyParallelism is the number of profiles deleted from at the same
+	// time, DefaultDestroyParallelism when not set.
+	DestroyParallelism int `json:"destroy_parallelism,omitempty"`
+	// StallRounds is the number of deletion rounds without progress after
+	// which frieza gives up, DefaultStallRounds when not set.
+	StallRounds int `json:"stall_rounds,omitempty"`
+	// Protected objects are never deleted, whatever the profile.
+	Protected []ProtectRule `json:"protected,omitempty"`
+	// RequiredSignatures is the number of trusted keys which must have signed
+	// a plan before it is applied.
+	RequiredSignatures int          `json:"required_signatures,omitempty"`
+	TrustedKeys        []TrustedKey `json:"trusted_keys,omitempty"`
+	// JournalPath is the file where delete attempts are recorded.
+	JournalPath string `json:"journal_path,omitempty"`
+	// RunFolderPath is where interrupted runs are kept to be resumed.
+	RunFolderPath string `json:"run_folder_path,omitempty"`
 }
 
 func ConfigNew() *Config {
@@ -65,6 +88,14 @@ func DefaultSnapshotFolderPath() (string, error) {
 	return path.Join(home, ".frieza", "snapshots"), nil
 }
 
+func DefaultRunFolderPath() (string, error) {
+	folderPath, err := DefaultConfigFolderPath()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(folderPath, "runs"), nil
+}
+
 func ConfigLoadWithDefault(customConfigPath *string) (*Config, error) {
 	config, err := ConfigLoad(customConfigPath)
 	if err != nil {
@@ -76,6 +107,18 @@ func ConfigLoadWithDefault(customConfigPath *string) (*Config, error) {
 			return nil, err
 		}
 	}
+	if len(config.JournalPath) == 0 {
+		config.JournalPath, err = DefaultJournalPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(config.RunFolderPath) == 0 {
+		config.RunFolderPath, err = DefaultRunFolderPath()
+		if err != nil {
+			return nil, err
+		}
+	}
 	return config, nil
 }
 
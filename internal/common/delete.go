@@ -0,0 +1,78 @@
+package common
+
+import "context"
+
+// DefaultStallRounds is the number of deletion rounds without progress after
+// which deletion stops.
+const DefaultStallRounds = 10
+
+// DefaultDestroyParallelism is the number of targets deleted at the same time
+// when no parallelism is configured.
+const DefaultDestroyParallelism = 4
+
+type DeleteErrorKind string
+
+const (
+	// DeleteErrorRetryable is a transient failure (server error, timeout, ...).
+	DeleteErrorRetryable DeleteErrorKind = "retryable"
+	// DeleteErrorDependency means another object prevents the deletion for now.
+	DeleteErrorDependency DeleteErrorKind = "dependency"
+	// DeleteErrorForbidden means the deletion is refused, retrying is useless.
+	DeleteErrorForbidden DeleteErrorKind = "forbidden"
+	// DeleteErrorNotFound means the object does not exist anymore.
+	DeleteErrorNotFound DeleteErrorKind = "not_found"
+	// DeleteErrorThrottled means the API asked to slow down, deletion is
+	// retried after a backoff.
+	DeleteErrorThrottled DeleteErrorKind = "throttled"
+)
+
+type DeleteResult struct {
+	Object Object
+	Kind   DeleteErrorKind
+	Err    error
+}
+
+type DeleteResults = map[ObjectType][]DeleteResult
+
+func DeleteSucceeded(object Object) DeleteResult {
+	return DeleteResult{Object: object}
+}
+
+func DeleteFailed(object Object, kind DeleteErrorKind, err error) DeleteResult {
+	return DeleteResult{Object: object, Kind: kind, Err: err}
+}
+
+// Deleted reports whether the object is gone, including when it had already
+// been deleted by someone else.
+func (result DeleteResult) Deleted() bool {
+	return result.Err == nil || result.Kind == DeleteErrorNotFound
+}
+
+// Retryable reports whether deleting the object again later may succeed.
+func (result DeleteResult) Retryable() bool {
+	return !result.Deleted() && result.Kind != DeleteErrorForbidden
+}
+
+// Throttled reports whether the API asked to slow down during deletions.
+func Throttled(results DeleteResults) bool {
+	for _, typeResults := range results {
+		for _, result := range typeResults {
+			if result.Kind == DeleteErrorThrottled {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func DeleteObjects(ctx context.Context, provider *Provider, objects Objects) DeleteResults {
+	results := make(DeleteResults)
+	for _, typeName := range (*provider).Types() {
+		objectList := objects[typeName]
+		if len(objectList) == 0 {
+			continue
+		}
+		results[typeName] = (*provider).DeleteObjects(ctx, typeName, objectList)
+	}
+	return results
+}
@@ -1,7 +1,12 @@
 package common
 
 import (
+	"encoding/json"
+	"fmt"
+	"maps"
 	"slices"
+	"strings"
+	"time"
 )
 
 type FilterKind string
@@ -9,11 +14,60 @@ type FilterKind string
 const (
 	FilterKindExclude FilterKind = "exclude"
 	FilterKindOnly    FilterKind = "only"
+	// FilterKindAge selects objects old enough, whatever their type.
+	FilterKindAge FilterKind = "age"
 )
 
 type ResourceFilterEnvelope struct {
 	Kind  FilterKind   `json:"kind"`
 	Types []ObjectType `json:"types,omitempty"`
+	// Tags must all be set on selected objects.
+	Tags map[string]string `json:"tags,omitempty"`
+	// ExcludeTags unselect objects having any of them.
+	ExcludeTags map[string]string `json:"exclude_tags,omitempty"`
+	// MinAge is the minimum age of objects selected by an age filter.
+	// Objects without creation date are never selected.
+	MinAge Duration `json:"min_age,omitempty"`
+	// Patterns select, for their type, only objects matching one of them.
+	Patterns []ObjectPattern `json:"patterns,omitempty"`
+	// ExcludePatterns unselect objects matching any of them.
+	ExcludePatterns []ObjectPattern `json:"exclude_patterns,omitempty"`
+	// Filters must also all select a type or an object.
+	Filters []*ResourceFilterEnvelope `json:"filters,omitempty"`
+}
+
+// SkippedObject is an object read but not selected, with the rule which
+// rejected it.
+type SkippedObject struct {
+	Object Object `json:"object"`
+	Rule   string `json:"rule"`
+}
+
+type SkippedObjects = map[ObjectType][]SkippedObject
+
+// Duration is serialized as a string like "72h".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// NewResourceFilter selects all types.
+func NewResourceFilter() *ResourceFilterEnvelope {
+	return NewResourceFilterExclude(nil)
 }
 
 func NewResourceFilterExclude(excluded []ObjectType) *ResourceFilterEnvelope {
@@ -30,13 +84,222 @@ func NewResourceFilterOnly(included []ObjectType) *ResourceFilterEnvelope {
 	}
 }
 
+func NewResourceFilterAge(minAge time.Duration) *ResourceFilterEnvelope {
+	return &ResourceFilterEnvelope{
+		Kind:   FilterKindAge,
+		MinAge: Duration(minAge),
+	}
+}
+
 func (f *ResourceFilterEnvelope) Select(typeName ObjectType) bool {
+	for _, filter := range f.Filters {
+		if !filter.Select(typeName) {
+			return false
+		}
+	}
 	switch f.Kind {
 	case FilterKindExclude:
 		return !slices.Contains(f.Types, typeName)
 	case FilterKindOnly:
 		return slices.Contains(f.Types, typeName)
+	case FilterKindAge:
+		return true
 	default:
 		return false
 	}
 }
+
+func (f *ResourceFilterEnvelope) HasTagFilters() bool {
+	if len(f.Tags) > 0 || len(f.ExcludeTags) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(f.Filters, (*ResourceFilterEnvelope).HasTagFilters)
+}
+
+// HasObjectFilters tells if objects must be checked one by one, not only
+// their type.
+func (f *ResourceFilterEnvelope) HasObjectFilters() bool {
+	if f.Kind == FilterKindAge || len(f.Tags) > 0 || len(f.ExcludeTags) > 0 {
+		return true
+	}
+	if len(f.Patterns) > 0 || len(f.ExcludePatterns) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(f.Filters, (*ResourceFilterEnvelope).HasObjectFilters)
+}
+
+// PatternTypes returns the types of all patterns of the filter and of its
+// nested filters, each once.
+func (f *ResourceFilterEnvelope) PatternTypes() []ObjectType {
+	types := PatternTypes(slices.Concat(f.Patterns, f.ExcludePatterns))
+	for _, filter := range f.Filters {
+		for _, typeName := range filter.PatternTypes() {
+			if !slices.Contains(types, typeName) {
+				types = append(types, typeName)
+			}
+		}
+	}
+	return types
+}
+
+// Reject returns the rule rejecting an object of type typeName, or an empty
+// string when the object is selected.
+func (f *ResourceFilterEnvelope) Reject(typeName ObjectType, object Object) string {
+	for _, filter := range f.Filters {
+		if rule := filter.Reject(typeName, object); len(rule) > 0 {
+			return rule
+		}
+	}
+	if f.Kind == FilterKindAge {
+		minAge := time.Duration(f.MinAge)
+		if object.CreatedAt == nil {
+			return fmt.Sprintf("older than %s: no creation date", minAge)
+		}
+		if time.Since(*object.CreatedAt) < minAge {
+			return fmt.Sprintf("older than %s: created at %s", minAge, object.CreatedAt.Format(time.RFC3339))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(f.Tags)) {
+		if tag, ok := object.Tags[key]; !ok || tag != f.Tags[key] {
+			return fmt.Sprintf("tag %s=%s missing", key, f.Tags[key])
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(f.ExcludeTags)) {
+		if tag, ok := object.Tags[key]; ok && tag == f.ExcludeTags[key] {
+			return fmt.Sprintf("excluded tag %s=%s", key, tag)
+		}
+	}
+	var unmatched []string
+	for _, pattern := range f.Patterns {
+		if pattern.Type != typeName {
+			continue
+		}
+		if pattern.Match(object) {
+			unmatched = nil
+			break
+		}
+		unmatched = append(unmatched, pattern.String())
+	}
+	if len(unmatched) > 0 {
+		return "does not match " + strings.Join(unmatched, " or ")
+	}
+	for _, pattern := range f.ExcludePatterns {
+		if pattern.Type == typeName && pattern.Match(object) {
+			return "excluded pattern " + pattern.String()
+		}
+	}
+	return ""
+}
+
+// SelectObject tells if an object of type typeName matches the tag, age and
+// pattern selectors.
+func (f *ResourceFilterEnvelope) SelectObject(typeName ObjectType, object Object) bool {
+	return len(f.Reject(typeName, object)) == 0
+}
+
+// SelectObjects splits objects of type typeName between selected and skipped
+// ones.
+func (f *ResourceFilterEnvelope) SelectObjects(typeName ObjectType, objects []Object) ([]Object, []SkippedObject) {
+	selected := make([]Object, 0, len(objects))
+	var skipped []SkippedObject
+	for _, object := range objects {
+		if rule := f.Reject(typeName, object); len(rule) > 0 {
+			skipped = append(skipped, SkippedObject{Object: object, Rule: rule})
+		} else {
+			selected = append(selected, object)
+		}
+	}
+	return selected, skipped
+}
+
+// With returns a copy of the filter also requiring filter.
+func (f *ResourceFilterEnvelope) With(filter *ResourceFilterEnvelope) *ResourceFilterEnvelope {
+	combined := *f
+	combined.Filters = append(slices.Clone(f.Filters), filter)
+	return &combined
+}
+
+// WithPatterns returns a copy of the filter with additional pattern
+// selectors.
+func (f *ResourceFilterEnvelope) WithPatterns(patterns []ObjectPattern, excludePatterns []ObjectPattern) *ResourceFilterEnvelope {
+	filter := *f
+	filter.Patterns = slices.Concat(f.Patterns, patterns)
+	filter.ExcludePatterns = slices.Concat(f.ExcludePatterns, excludePatterns)
+	return &filter
+}
+
+// WithTags returns a copy of the filter with additional tag selectors.
+func (f *ResourceFilterEnvelope) WithTags(tags map[string]string, excludeTags map[string]string) *ResourceFilterEnvelope {
+	filter := *f
+	filter.Tags = maps.Clone(f.Tags)
+	filter.ExcludeTags = maps.Clone(f.ExcludeTags)
+	if len(tags) > 0 && filter.Tags == nil {
+		filter.Tags = make(map[string]string)
+	}
+	if len(excludeTags) > 0 && filter.ExcludeTags == nil {
+		filter.ExcludeTags = make(map[string]string)
+	}
+	maps.Copy(filter.Tags, tags)
+	maps.Copy(filter.ExcludeTags, excludeTags)
+	return &filter
+}
+
+func (f *ResourceFilterEnvelope) String() string {
+	var parts []string
+	if len(f.Types) > 0 {
+		parts = append(parts, fmt.Sprintf("%s types: %s", f.Kind, strings.Join(f.Types, ", ")))
+	}
+	if len(f.Tags) > 0 {
+		parts = append(parts, "tags: "+FormatTags(f.Tags))
+	}
+	if len(f.ExcludeTags) > 0 {
+		parts = append(parts, "excluded tags: "+FormatTags(f.ExcludeTags))
+	}
+	if len(f.Patterns) > 0 {
+		parts = append(parts, "patterns: "+joinPatterns(f.Patterns))
+	}
+	if len(f.ExcludePatterns) > 0 {
+		parts = append(parts, "excluded patterns: "+joinPatterns(f.ExcludePatterns))
+	}
+	if f.Kind == FilterKindAge {
+		parts = append(parts, fmt.Sprintf("older than %s", time.Duration(f.MinAge)))
+	}
+	for _, filter := range f.Filters {
+		if filterString := filter.String(); filterString != "all types" {
+			parts = append(parts, filterString)
+		}
+	}
+	if len(parts) == 0 {
+		return "all types"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func joinPatterns(patterns []ObjectPattern) string {
+	rules := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		rules = append(rules, pattern.String())
+	}
+	return strings.Join(rules, ", ")
+}
+
+// ParseTags reads a comma separated list of key=value pairs.
+func ParseTags(tags string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	for _, pair := range strings.Split(tags, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || len(key) == 0 {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", pair)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+func FormatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,124 @@
+package common
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DependencyGraph orders the deletion of a provider's object types.
+type DependencyGraph struct {
+	types     []ObjectType
+	dependsOn map[ObjectType][]ObjectType
+}
+
+// NewDependencyGraph builds the graph of a provider from its declared
+// dependencies. Providers which do not declare any get a graph without
+// edges, where every type can be deleted at once.
+func NewDependencyGraph(provider *Provider) (*DependencyGraph, error) {
+	graph := &DependencyGraph{
+		types:     (*provider).Types(),
+		dependsOn: make(map[ObjectType][]ObjectType),
+	}
+	dependencyProvider, ok := (*provider).(DependencyProvider)
+	if !ok {
+		return graph, nil
+	}
+	dependencies := dependencyProvider.Dependencies()
+	for typeName, before := range dependencies {
+		if !slices.Contains(graph.types, typeName) {
+			return nil, fmt.Errorf("dependency declared on unknown type %s", typeName)
+		}
+		for _, dependency := range before {
+			if !slices.Contains(graph.types, dependency) {
+				return nil, fmt.Errorf("type %s depends on unknown type %s", typeName, dependency)
+			}
+		}
+	}
+	if err := checkCycles(graph.types, dependencies); err != nil {
+		return nil, err
+	}
+	for _, typeName := range graph.types {
+		graph.dependsOn[typeName] = transitiveDependencies(typeName, dependencies)
+	}
+	return graph, nil
+}
+
+func checkCycles(types []ObjectType, dependencies map[ObjectType][]ObjectType) error {
+	inDegree := make(map[ObjectType]int)
+	dependents := make(map[ObjectType][]ObjectType)
+	for _, typeName := range types {
+		for _, dependency := range dependencies[typeName] {
+			inDegree[typeName]++
+			dependents[dependency] = append(dependents[dependency], typeName)
+		}
+	}
+	var queue []ObjectType
+	for _, typeName := range types {
+		if inDegree[typeName] == 0 {
+			queue = append(queue, typeName)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		typeName := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, dependent := range dependents[typeName] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	if visited != len(types) {
+		var cycle []ObjectType
+		for _, typeName := range types {
+			if inDegree[typeName] > 0 {
+				cycle = append(cycle, typeName)
+			}
+		}
+		return fmt.Errorf("dependency cycle between types %v", cycle)
+	}
+	return nil
+}
+
+func transitiveDependencies(typeName ObjectType, dependencies map[ObjectType][]ObjectType) []ObjectType {
+	var out []ObjectType
+	stack := slices.Clone(dependencies[typeName])
+	for len(stack) > 0 {
+		dependency := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if slices.Contains(out, dependency) {
+			continue
+		}
+		out = append(out, dependency)
+		stack = append(stack, dependencies[dependency]...)
+	}
+	return out
+}
+
+// Ready returns, in provider's order, the types which still have objects and
+// whose dependencies have all been deleted.
+func (graph *DependencyGraph) Ready(remaining Objects) []ObjectType {
+	var ready []ObjectType
+	for _, typeName := range graph.types {
+		if len(remaining[typeName]) == 0 {
+			continue
+		}
+		if len(graph.BlockedBy(typeName, remaining)) == 0 {
+			ready = append(ready, typeName)
+		}
+	}
+	return ready
+}
+
+// BlockedBy returns the dependencies of a type which still have objects.
+func (graph *DependencyGraph) BlockedBy(typeName ObjectType, remaining Objects) []ObjectType {
+	var blockers []ObjectType
+	for _, dependency := range graph.dependsOn[typeName] {
+		if len(remaining[dependency]) > 0 {
+			blockers = append(blockers, dependency)
+		}
+	}
+	return blockers
+}
@@ -0,0 +1,134 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// graphTestProvider only declares types and dependencies.
+type graphTestProvider struct {
+	types        []ObjectType
+	dependencies map[ObjectType][]ObjectType
+}
+
+func (provider *graphTestProvider) Name() string                       { return "graph_test" }
+func (provider *graphTestProvider) Types() []ObjectType                { return provider.types }
+func (provider *graphTestProvider) AuthTest(ctx context.Context) error { return nil }
+func (provider *graphTestProvider) StringObject(object Object, typeName string) string {
+	return object.Id
+}
+
+func (provider *graphTestProvider) ReadObjects(ctx context.Context, typeName string) ([]Object, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (provider *graphTestProvider) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
+	return nil
+}
+
+func (provider *graphTestProvider) Dependencies() map[ObjectType][]ObjectType {
+	return provider.dependencies
+}
+
+// newTestGraph builds the graph of a provider where nets are deleted after
+// security groups, themselves deleted after vms.
+func newTestGraph(t *testing.T) *DependencyGraph {
+	t.Helper()
+	var provider Provider = &graphTestProvider{
+		types: []ObjectType{"vm", "security_group", "net", "keypair"},
+		dependencies: map[ObjectType][]ObjectType{
+			"security_group": {"vm"},
+			"net":            {"security_group"},
+		},
+	}
+	graph, err := NewDependencyGraph(&provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graph
+}
+
+func TestCheckCycles(t *testing.T) {
+	types := []ObjectType{"a", "b", "c"}
+	if err := checkCycles(types, map[ObjectType][]ObjectType{"b": {"a"}, "c": {"a", "b"}}); err != nil {
+		t.Errorf("acyclic dependencies rejected: %s", err)
+	}
+	if err := checkCycles(types, map[ObjectType][]ObjectType{"a": {"c"}, "b": {"a"}, "c": {"b"}}); err == nil {
+		t.Error("cycle a -> c -> b -> a accepted")
+	}
+	if err := checkCycles(types, map[ObjectType][]ObjectType{"a": {"a"}}); err == nil {
+		t.Error("type depending on itself accepted")
+	}
+}
+
+func TestNewDependencyGraphErrors(t *testing.T) {
+	for name, dependencies := range map[string]map[ObjectType][]ObjectType{
+		"unknown type":       {"disk": {"vm"}},
+		"unknown dependency": {"vm": {"disk"}},
+		"cycle":              {"vm": {"net"}, "net": {"vm"}},
+	} {
+		var provider Provider = &graphTestProvider{types: []ObjectType{"vm", "net"}, dependencies: dependencies}
+		if _, err := NewDependencyGraph(&provider); err == nil {
+			t.Errorf("%s: graph built", name)
+		}
+	}
+}
+
+func TestTransitiveDependencies(t *testing.T) {
+	dependencies := map[ObjectType][]ObjectType{
+		"b": {"a"},
+		"c": {"b", "a"},
+		"d": {"c"},
+	}
+	got := transitiveDependencies("d", dependencies)
+	slices.Sort(got)
+	if !slices.Equal(got, []ObjectType{"a", "b", "c"}) {
+		t.Errorf("dependencies of d are %v, expected a, b and c once each", got)
+	}
+	if got := transitiveDependencies("a", dependencies); len(got) != 0 {
+		t.Errorf("a has dependencies %v", got)
+	}
+}
+
+func TestReady(t *testing.T) {
+	graph := newTestGraph(t)
+	remaining := Objects{
+		"vm":             {NewObject("i-1")},
+		"security_group": {NewObject("sg-1")},
+		"net":            {NewObject("vpc-1")},
+		"keypair":        {NewObject("kp-1")},
+	}
+	if got := graph.Ready(remaining); !slices.Equal(got, []ObjectType{"vm", "keypair"}) {
+		t.Errorf("ready types are %v, expected vm and keypair", got)
+	}
+	remaining["vm"] = nil
+	remaining["keypair"] = nil
+	if got := graph.Ready(remaining); !slices.Equal(got, []ObjectType{"security_group"}) {
+		t.Errorf("ready types are %v, expected security_group", got)
+	}
+	remaining["security_group"] = nil
+	if got := graph.Ready(remaining); !slices.Equal(got, []ObjectType{"net"}) {
+		t.Errorf("ready types are %v, expected net", got)
+	}
+	remaining["net"] = nil
+	if got := graph.Ready(remaining); len(got) != 0 {
+		t.Errorf("ready types are %v without remaining objects", got)
+	}
+}
+
+func TestBlockedBy(t *testing.T) {
+	graph := newTestGraph(t)
+	remaining := Objects{
+		"vm":  {NewObject("i-1")},
+		"net": {NewObject("vpc-1")},
+	}
+	// Nets also wait for vms, through security groups.
+	if got := graph.BlockedBy("net", remaining); !slices.Equal(got, []ObjectType{"vm"}) {
+		t.Errorf("net blocked by %v, expected vm", got)
+	}
+	if got := graph.BlockedBy("vm", remaining); len(got) != 0 {
+		t.Errorf("vm blocked by %v", got)
+	}
+}
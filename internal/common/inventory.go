@@ -0,0 +1,182 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// DefaultParallelism is the number of types read at the same time when no
+// parallelism is configured.
+const DefaultParallelism = 8
+
+// ConcurrencyLimiter can be implemented by providers whose API does not
+// accept many simultaneous calls. MaxConcurrency is the maximum number of
+// types of this provider read at the same time.
+type ConcurrencyLimiter interface {
+	MaxConcurrency() int
+}
+
+type InventoryTarget struct {
+	Name     string
+	Provider *Provider
+	Types    []ObjectType
+	Filters  *ResourceFilterEnvelope
+	// Untagged lists the selected types skipped because the provider cannot
+	// apply tag filters on them.
+	Untagged []ObjectType
+	// SkipTags lets providers skip the API calls reading tags, when neither
+	// filters nor protect rules use them.
+	SkipTags bool
+}
+
+type skipTagsKey struct{}
+
+// WithoutTags tells providers reading objects with ctx that tags are not
+// needed.
+func WithoutTags(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipTagsKey{}, true)
+}
+
+// TagsWanted tells providers whether they must read tags which cost extra API
+// calls, like tags of storage buckets.
+func TagsWanted(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipTagsKey{}).(bool)
+	return !skip
+}
+
+// NewInventoryTarget selects the types of provider matching filters.
+func NewInventoryTarget(name string, provider *Provider, filters *ResourceFilterEnvelope) InventoryTarget {
+	target := InventoryTarget{Name: name, Provider: provider, Filters: filters}
+	var taggedTypes []ObjectType
+	if tagProvider, ok := (*provider).(TagProvider); ok {
+		taggedTypes = tagProvider.TaggedTypes()
+	}
+	for _, typeName := range (*provider).Types() {
+		if filters != nil && !filters.Select(typeName) {
+			continue
+		}
+		if filters != nil && filters.HasTagFilters() && !slices.Contains(taggedTypes, typeName) {
+			target.Untagged = append(target.Untagged, typeName)
+			continue
+		}
+		target.Types = append(target.Types, typeName)
+	}
+	return target
+}
+
+// Inventory reads objects of several providers with a bounded number of
+// concurrent API calls.
+type Inventory struct {
+	parallelism int
+}
+
+func NewInventory(parallelism int) *Inventory {
+	if parallelism < 1 {
+		parallelism = DefaultParallelism
+	}
+	return &Inventory{parallelism: parallelism}
+}
+
+// InventoryResult holds what was read for a target.
+type InventoryResult struct {
+	Objects Objects
+	// Skipped are the objects read but rejected by the target filters.
+	Skipped SkippedObjects
+}
+
+// Read returns the objects of each target, in the same order as targets.
+// Types which cannot be read are missing from the result and all read errors
+// are returned joined.
+func (inventory *Inventory) Read(ctx context.Context, targets []InventoryTarget) ([]Objects, error) {
+	results, err := inventory.Collect(ctx, targets)
+	objects := make([]Objects, len(results))
+	for i := range results {
+		objects[i] = results[i].Objects
+	}
+	return objects, err
+}
+
+// Collect is like Read but also returns the objects skipped by filters.
+func (inventory *Inventory) Collect(ctx context.Context, targets []InventoryTarget) ([]InventoryResult, error) {
+	return inventory.CollectNew(ctx, targets, nil)
+}
+
+// CollectNew is like Collect but only returns objects whose identifier is not
+// in known, which has one entry per target. Objects are streamed from
+// providers so that known ones are never all held in memory.
+func (inventory *Inventory) CollectNew(ctx context.Context, targets []InventoryTarget, known []ObjectIdSets) ([]InventoryResult, error) {
+	results := make([]InventoryResult, len(targets))
+	errs := make([][]error, len(targets))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, inventory.parallelism)
+	for i, target := range targets {
+		results[i] = InventoryResult{Objects: make(Objects), Skipped: make(SkippedObjects)}
+		errs[i] = make([]error, len(target.Types))
+		limit := make(chan struct{}, providerConcurrency(target.Provider, inventory.parallelism))
+		for j, typeName := range target.Types {
+			wg.Go(func() {
+				limit <- struct{}{}
+				defer func() { <-limit }()
+				workers <- struct{}{}
+				defer func() { <-workers }()
+
+				if err := ctx.Err(); err != nil {
+					errs[i][j] = fmt.Errorf("%s: read %s: %w", target.Name, typeName, err)
+					return
+				}
+				ctx := ctx
+				if target.SkipTags {
+					ctx = WithoutTags(ctx)
+				}
+				var knownIds IdSet
+				if known != nil {
+					knownIds = known[i][typeName]
+				}
+				filtered := target.Filters != nil && target.Filters.HasObjectFilters()
+				objects := make([]Object, 0)
+				var skipped []SkippedObject
+				for object, err := range StreamObjects(ctx, target.Provider, typeName) {
+					if err != nil {
+						errs[i][j] = fmt.Errorf("%s: %w", target.Name, err)
+						return
+					}
+					if _, found := knownIds[object.Id]; found {
+						continue
+					}
+					if filtered {
+						if rule := target.Filters.Reject(typeName, object); len(rule) > 0 {
+							skipped = append(skipped, SkippedObject{Object: object, Rule: rule})
+							continue
+						}
+					}
+					objects = append(objects, object)
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				results[i].Objects[typeName] = objects
+				if len(skipped) > 0 {
+					results[i].Skipped[typeName] = skipped
+				}
+			})
+		}
+	}
+	wg.Wait()
+	var joined []error
+	for i := range errs {
+		joined = append(joined, errs[i]...)
+	}
+	return results, errors.Join(joined...)
+}
+
+func providerConcurrency(provider *Provider, parallelism int) int {
+	if limiter, ok := (*provider).(ConcurrencyLimiter); ok {
+		if limit := limiter.MaxConcurrency(); limit > 0 && limit < parallelism {
+			return limit
+		}
+	}
+	return parallelism
+}
@@ -0,0 +1,197 @@
+package common
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// JournalResultAttempted is written before asking a provider to delete an
+	// object, its outcome follows once known. An attempt without outcome was
+	// interrupted, the object may be gone.
+	JournalResultAttempted = "attempted"
+	JournalResultDeleted   = "deleted"
+	JournalResultNotFound  = "not_found"
+	JournalResultFailed    = "failed"
+	// JournalResultThrottled is an attempt rejected by the API rate limit,
+	// made again later.
+	JournalResultThrottled = "throttled"
+)
+
+// JournalEntry records one delete attempt.
+type JournalEntry struct {
+	Time time.Time `json:"time"`
+	// Run identifies the command which made the attempt.
+	Run      string          `json:"run"`
+	Command  string          `json:"command"`
+	Profile  string          `json:"profile"`
+	Provider string          `json:"provider"`
+	Type     ObjectType      `json:"type"`
+	Id       string          `json:"id"`
+	Name     string          `json:"name,omitempty"`
+	Result   string          `json:"result"`
+	Kind     DeleteErrorKind `json:"kind,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Journal appends delete attempts to a JSON lines file, entries are never
+// modified once written.
+type Journal struct {
+	mutex   sync.Mutex
+	file    *os.File
+	run     string
+	command string
+}
+
+func DefaultJournalPath() (string, error) {
+	folderPath, err := DefaultConfigFolderPath()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(folderPath, "journal.jsonl"), nil
+}
+
+// NewRunId returns a unique identifier for a run of a command, starting with
+// its date.
+func NewRunId() string {
+	return time.Now().UTC().Format("20060102-150405") + "-" + strings.ToLower(rand.Text()[:6])
+}
+
+// OpenJournal opens the journal at path for a run of command.
+func OpenJournal(journalPath string, run string, command string) (*Journal, error) {
+	if err := os.MkdirAll(path.Dir(journalPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: file, run: run, command: command}, nil
+}
+
+// RecordAttempts appends the objects of a profile about to be deleted,
+// before the provider is called.
+func (journal *Journal) RecordAttempts(profile string, provider string, objects Objects) error {
+	var entries []JournalEntry
+	now := time.Now().UTC()
+	for typeName, typeObjects := range objects {
+		for _, object := range typeObjects {
+			entry := journal.entry(now, profile, provider, typeName, object)
+			entry.Result = JournalResultAttempted
+			entries = append(entries, entry)
+		}
+	}
+	return journal.write(entries)
+}
+
+// Record appends the results of delete attempts made on a profile.
+func (journal *Journal) Record(profile string, provider string, results DeleteResults) error {
+	var entries []JournalEntry
+	now := time.Now().UTC()
+	for typeName, typeResults := range results {
+		for _, result := range typeResults {
+			entry := journal.entry(now, profile, provider, typeName, result.Object)
+			entry.Result = JournalResultDeleted
+			if result.Err != nil {
+				switch result.Kind {
+				case DeleteErrorNotFound:
+					entry.Result = JournalResultNotFound
+				case DeleteErrorThrottled:
+					entry.Result = JournalResultThrottled
+				default:
+					entry.Result = JournalResultFailed
+				}
+				entry.Kind = result.Kind
+				entry.Error = result.Err.Error()
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return journal.write(entries)
+}
+
+func (journal *Journal) entry(now time.Time, profile string, provider string, typeName ObjectType, object Object) JournalEntry {
+	return JournalEntry{
+		Time:     now,
+		Run:      journal.run,
+		Command:  journal.command,
+		Profile:  profile,
+		Provider: provider,
+		Type:     typeName,
+		Id:       object.Id,
+		Name:     object.Name,
+	}
+}
+
+func (journal *Journal) write(entries []JournalEntry) error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+	var lines []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	// A single write keeps entries of concurrent frieza runs whole.
+	_, err := journal.file.Write(lines)
+	return err
+}
+
+func (journal *Journal) Close() error {
+	return journal.file.Close()
+}
+
+// JournalQuery selects journal entries, empty fields select everything.
+type JournalQuery struct {
+	Since   time.Time
+	Until   time.Time
+	Profile string
+	Type    ObjectType
+}
+
+func (query JournalQuery) Match(entry JournalEntry) bool {
+	return (query.Since.IsZero() || !entry.Time.Before(query.Since)) &&
+		(query.Until.IsZero() || entry.Time.Before(query.Until)) &&
+		(len(query.Profile) == 0 || entry.Profile == query.Profile) &&
+		(len(query.Type) == 0 || entry.Type == query.Type)
+}
+
+// ReadJournal returns the entries of the journal at path matching query, in
+// the order they were written. A missing journal has no entry.
+func ReadJournal(journalPath string, query JournalQuery) ([]JournalEntry, error) {
+	file, err := os.Open(journalPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", journalPath, line, err)
+		}
+		if query.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+type Object struct {
+	Id         string            `json:"id"`
+	Name       string            `json:"name,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	CreatedAt  *time.Time        `json:"created_at,omitempty"`
+	Region     string            `json:"region,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func NewObject(id string) Object {
+	return Object{Id: id}
+}
+
+// ObjectIds returns the identifiers of objects, in the same order.
+func ObjectIds(objects []Object) []string {
+	ids := make([]string, 0, len(objects))
+	for _, object := range objects {
+		ids = append(ids, object.Id)
+	}
+	return ids
+}
+
+func (object *Object) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	utc := createdAt.UTC()
+	object.CreatedAt = &utc
+}
+
+func (object *Object) SetAttribute(key string, value string) {
+	if len(value) == 0 {
+		return
+	}
+	if object.Attributes == nil {
+		object.Attributes = make(map[string]string)
+	}
+	object.Attributes[key] = value
+}
+
+func (object Object) String() string {
+	if len(object.Name) == 0 || object.Name == object.Id {
+		return object.Id
+	}
+	return fmt.Sprintf("%s (%s)", object.Id, object.Name)
+}
@@ -0,0 +1,130 @@
+package common
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type PatternField string
+
+const (
+	// PatternFieldAny matches the identifier or the name of objects.
+	PatternFieldAny  PatternField = ""
+	PatternFieldId   PatternField = "id"
+	PatternFieldName PatternField = "name"
+)
+
+// ObjectPattern matches objects of a type by identifier or name. It is
+// written as "type:[id|name]=regex" or "type:[id|name]~glob", the field being
+// optional ("bucket:^tmp-", "bucket:~tmp-*").
+type ObjectPattern struct {
+	Type    ObjectType
+	Field   PatternField
+	Pattern string
+	Glob    bool
+	regexp  *regexp.Regexp
+}
+
+func ParseObjectPattern(rule string) (ObjectPattern, error) {
+	typeName, expression, found := strings.Cut(rule, ":")
+	if !found || len(typeName) == 0 || len(expression) == 0 {
+		return ObjectPattern{}, fmt.Errorf("invalid pattern %q, expected type:[id|name]=regex or type:[id|name]~glob", rule)
+	}
+	pattern := ObjectPattern{Type: typeName, Pattern: expression}
+	for _, field := range []PatternField{PatternFieldId, PatternFieldName, PatternFieldAny} {
+		if value, ok := strings.CutPrefix(expression, string(field)+"~"); ok {
+			pattern.Field, pattern.Pattern, pattern.Glob = field, value, true
+			break
+		}
+		if value, ok := strings.CutPrefix(expression, string(field)+"="); ok && field != PatternFieldAny {
+			pattern.Field, pattern.Pattern = field, value
+			break
+		}
+	}
+	if len(pattern.Pattern) == 0 {
+		return ObjectPattern{}, fmt.Errorf("invalid pattern %q: empty expression", rule)
+	}
+	if pattern.Glob {
+		if _, err := path.Match(pattern.Pattern, ""); err != nil {
+			return ObjectPattern{}, fmt.Errorf("invalid pattern %q: %w", rule, err)
+		}
+		return pattern, nil
+	}
+	compiled, err := regexp.Compile(pattern.Pattern)
+	if err != nil {
+		return ObjectPattern{}, fmt.Errorf("invalid pattern %q: %w", rule, err)
+	}
+	pattern.regexp = compiled
+	return pattern, nil
+}
+
+// ParseObjectPatterns reads patterns given one by one, as they can contain
+// commas.
+func ParseObjectPatterns(rules []string) ([]ObjectPattern, error) {
+	var patterns []ObjectPattern
+	for _, rule := range rules {
+		pattern, err := ParseObjectPattern(rule)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// PatternTypes returns the types of patterns, each once.
+func PatternTypes(patterns []ObjectPattern) []ObjectType {
+	var types []ObjectType
+	for _, pattern := range patterns {
+		if !slices.Contains(types, pattern.Type) {
+			types = append(types, pattern.Type)
+		}
+	}
+	return types
+}
+
+func (pattern ObjectPattern) Match(object Object) bool {
+	switch pattern.Field {
+	case PatternFieldId:
+		return pattern.matchString(object.Id)
+	case PatternFieldName:
+		return len(object.Name) > 0 && pattern.matchString(object.Name)
+	default:
+		return pattern.matchString(object.Id) ||
+			(len(object.Name) > 0 && pattern.matchString(object.Name))
+	}
+}
+
+func (pattern ObjectPattern) matchString(value string) bool {
+	if pattern.Glob {
+		matched, _ := path.Match(pattern.Pattern, value)
+		return matched
+	}
+	return pattern.regexp.MatchString(value)
+}
+
+func (pattern ObjectPattern) String() string {
+	operator := "="
+	if pattern.Glob {
+		operator = "~"
+	} else if pattern.Field == PatternFieldAny {
+		operator = ""
+	}
+	return pattern.Type + ":" + string(pattern.Field) + operator + pattern.Pattern
+}
+
+func (pattern ObjectPattern) MarshalText() ([]byte, error) {
+	return []byte(pattern.String()), nil
+}
+
+func (pattern *ObjectPattern) UnmarshalText(text []byte) error {
+	parsed, err := ParseObjectPattern(string(text))
+	if err != nil {
+		return err
+	}
+	*pattern = parsed
+	return nil
+}
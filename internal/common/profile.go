@@ -11,6 +11,8 @@ type Profile struct {
 	Provider  string         `json:"provider,omitempty"`
 	Providers []string       `json:"providers,omitempty"`
 	Config    ProviderConfig `json:"config"`
+	// Protected objects are never deleted from this profile.
+	Protected []ProtectRule `json:"protected,omitempty"`
 }
 
 func (p *Profile) GetProviders() ([]string, error) {
@@ -48,5 +50,12 @@ func (profile Profile) String() string {
 	for key, value := range profile.Config {
 		fmt.Fprintf(&outBuilder, "  - %v: %v\n", key, value)
 	}
+
+	if len(profile.Protected) > 0 {
+		outBuilder.WriteString("protected:\n")
+		for _, rule := range profile.Protected {
+			fmt.Fprintf(&outBuilder, "  - %v\n", rule)
+		}
+	}
 	return outBuilder.String()
 }
@@ -0,0 +1,155 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+)
+
+// ProtectRule describes objects frieza must never delete. All set fields must
+// match: Type restricts the rule to a type, Id is an exact identifier, Name a
+// glob pattern on names and Tags must all be set on the object.
+type ProtectRule struct {
+	Type ObjectType        `json:"type,omitempty"`
+	Id   string            `json:"id,omitempty"`
+	Name string            `json:"name,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+func (rule ProtectRule) Validate() error {
+	if len(rule.Id) == 0 && len(rule.Name) == 0 && len(rule.Tags) == 0 {
+		return errors.New("protect rule needs an id, a name or tags")
+	}
+	if _, err := path.Match(rule.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q: %w", rule.Name, err)
+	}
+	return nil
+}
+
+func (rule ProtectRule) Match(typeName ObjectType, object Object) bool {
+	if len(rule.Type) > 0 && rule.Type != typeName {
+		return false
+	}
+	if len(rule.Id) > 0 && rule.Id != object.Id {
+		return false
+	}
+	if len(rule.Name) > 0 {
+		if matched, _ := path.Match(rule.Name, object.Name); !matched {
+			return false
+		}
+	}
+	for key, value := range rule.Tags {
+		if tag, ok := object.Tags[key]; !ok || tag != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (rule ProtectRule) Equal(other ProtectRule) bool {
+	return rule.Type == other.Type &&
+		rule.Id == other.Id &&
+		rule.Name == other.Name &&
+		maps.Equal(rule.Tags, other.Tags)
+}
+
+func (rule ProtectRule) String() string {
+	var parts []string
+	if len(rule.Type) > 0 {
+		parts = append(parts, "type="+rule.Type)
+	}
+	if len(rule.Id) > 0 {
+		parts = append(parts, "id="+rule.Id)
+	}
+	if len(rule.Name) > 0 {
+		parts = append(parts, "name="+rule.Name)
+	}
+	if len(rule.Tags) > 0 {
+		parts = append(parts, "tags="+FormatTags(rule.Tags))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ProtectedRules returns the global rules followed by the ones of profile.
+func (config *Config) ProtectedRules(profile *Profile) []ProtectRule {
+	rules := append([]ProtectRule{}, config.Protected...)
+	if profile != nil {
+		rules = append(rules, profile.Protected...)
+	}
+	return rules
+}
+
+// Protect removes from objects the ones matching a rule and returns them.
+func Protect(rules []ProtectRule, objects *Objects) SkippedObjects {
+	protected := make(SkippedObjects)
+	if len(rules) == 0 {
+		return protected
+	}
+	for typeName, typeObjects := range *objects {
+		kept := make([]Object, 0, len(typeObjects))
+		for _, object := range typeObjects {
+			if rule, found := matchingRule(rules, typeName, object); found {
+				protected[typeName] = append(protected[typeName], SkippedObject{
+					Object: object,
+					Rule:   "protected: " + rule.String(),
+				})
+				continue
+			}
+			kept = append(kept, object)
+		}
+		(*objects)[typeName] = kept
+	}
+	return protected
+}
+
+func matchingRule(rules []ProtectRule, typeName ObjectType, object Object) (ProtectRule, bool) {
+	for _, rule := range rules {
+		if rule.Match(typeName, object) {
+			return rule, true
+		}
+	}
+	return ProtectRule{}, false
+}
+
+type protectRulesKey struct{}
+
+// WithProtectRules passes the protect rules of a deletion to providers, which
+// must not alter protected objects linked to the ones they delete, like a
+// protected VM using a public IP being deleted.
+func WithProtectRules(ctx context.Context, rules []ProtectRule) context.Context {
+	return context.WithValue(ctx, protectRulesKey{}, rules)
+}
+
+// HasProtectRules tells providers whether linked objects must be checked,
+// which may need extra API calls.
+func HasProtectRules(ctx context.Context) bool {
+	rules, _ := ctx.Value(protectRulesKey{}).([]ProtectRule)
+	return len(rules) > 0
+}
+
+// ProtectedError is returned when deleting an object would alter a protected
+// object linked to it.
+type ProtectedError struct {
+	Type   ObjectType
+	Object Object
+	Rule   ProtectRule
+}
+
+func (err *ProtectedError) Error() string {
+	return fmt.Sprintf("linked %s %s is protected: %s", err.Type, err.Object, err.Rule)
+}
+
+// CheckProtected returns a *ProtectedError when one of the objects is
+// protected by the rules passed with WithProtectRules.
+func CheckProtected(ctx context.Context, typeName ObjectType, objects []Object) error {
+	rules, _ := ctx.Value(protectRulesKey{}).([]ProtectRule)
+	for _, object := range objects {
+		if rule, found := matchingRule(rules, typeName, object); found {
+			return &ProtectedError{Type: typeName, Object: object, Rule: rule}
+		}
+	}
+	return nil
+}
@@ -1,12 +1,13 @@
 package common
 
-import "context"
-
-type (
-	ObjectType = string
-	Object     = string
+import (
+	"context"
+	"iter"
+	"log"
 )
 
+type ObjectType = string
+
 type ProviderConfig = map[string]string
 
 type Provider interface {
@@ -14,6 +15,51 @@ type Provider interface {
 	Types() []ObjectType
 	AuthTest(ctx context.Context) error
 	ReadObjects(ctx context.Context, typeName string) ([]Object, error)
-	DeleteObjects(ctx context.Context, typeName string, objects []Object)
-	StringObject(object string, typeName string) string
+	DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult
+	StringObject(object Object, typeName string) string
+}
+
+// DependencyProvider can be implemented by providers whose object types must
+// be deleted in a specific order. Dependencies maps an object type to the
+// types which must be emptied before its objects can be deleted.
+type DependencyProvider interface {
+	Dependencies() map[ObjectType][]ObjectType
+}
+
+// TagProvider can be implemented by providers filling Object tags.
+// TaggedTypes lists the types which can be selected by tags.
+type TagProvider interface {
+	TaggedTypes() []ObjectType
+}
+
+// ExistenceChecker can be implemented by providers able to read specific
+// objects without listing their whole type. ExistingObjects returns those of
+// objects which still exist, or errors.ErrUnsupported when typeName cannot be
+// read by ID.
+type ExistenceChecker interface {
+	ExistingObjects(ctx context.Context, typeName string, objects []Object) ([]Object, error)
+}
+
+// ObjectStreamer can be implemented by providers having types too large to be
+// read at once, like objects of storage buckets. StreamObjects yields objects
+// as they are read, the listing stops at the first error.
+type ObjectStreamer interface {
+	StreamObjects(ctx context.Context, typeName string) iter.Seq2[Object, error]
+}
+
+type loggerKey struct{}
+
+// WithLogger makes providers write the messages of calls made with ctx to
+// logger, like a logger prefixing them with the profile deleted from.
+func WithLogger(ctx context.Context, logger *log.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// ContextLogger returns the logger providers write messages to, the standard
+// logger unless set with WithLogger.
+func ContextLogger(ctx context.Context) *log.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*log.Logger); ok {
+		return logger
+	}
+	return log.Default()
 }
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ProviderConfigOption describes a key of a provider configuration.
+type ProviderConfigOption struct {
+	Name        string
+	Description string
+}
+
+// ProviderRegistration describes how to build and configure a provider.
+type ProviderRegistration struct {
+	Name string
+	// Description is shown when creating a profile, e.g. "S3".
+	Description string
+	New         func(config ProviderConfig, debug bool) (Provider, error)
+	Types       func() []ObjectType
+	// Config lists the profile options of the provider.
+	Config []ProviderConfigOption
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = make(map[string]ProviderRegistration)
+)
+
+// RegisterProvider makes a provider available to profiles. It is meant to be
+// called from the init function of provider packages and panics if the name
+// is already registered.
+func RegisterProvider(registration ProviderRegistration) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, exists := registry[registration.Name]; exists {
+		panic(fmt.Sprintf("provider %s registered twice", registration.Name))
+	}
+	registry[registration.Name] = registration
+}
+
+// RegisteredProviders returns all registrations sorted by name.
+func RegisteredProviders() []ProviderRegistration {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	registrations := make([]ProviderRegistration, 0, len(registry))
+	for _, name := range slices.Sorted(maps.Keys(registry)) {
+		registrations = append(registrations, registry[name])
+	}
+	return registrations
+}
+
+func LookupProvider(name string) (ProviderRegistration, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	registration, found := registry[name]
+	return registration, found
+}
+
+// NewProvider builds a registered provider.
+func NewProvider(name string, config ProviderConfig, debug bool) (Provider, error) {
+	registration, found := LookupProvider(name)
+	if !found {
+		return nil, fmt.Errorf("provider %s not found", name)
+	}
+	return registration.New(config, debug)
+}
+
+// ProviderConstructor adapts the New function of a provider package to
+// ProviderRegistration.New.
+func ProviderConstructor[T Provider](newProvider func(config ProviderConfig, debug bool) (T, error)) func(ProviderConfig, bool) (Provider, error) {
+	return func(config ProviderConfig, debug bool) (Provider, error) {
+		provider, err := newProvider(config, debug)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+}
@@ -0,0 +1,157 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitOption is the profile option limiting the API calls of a provider,
+// making at most defaultRate calls per second when not set, 0 meaning no
+// limit.
+func RateLimitOption(defaultRate float64) ProviderConfigOption {
+	description := "maximum number of API calls per second (default: no limit)"
+	if defaultRate > 0 {
+		description = fmt.Sprintf("maximum number of API calls per second (default: %g)", defaultRate)
+	}
+	return ProviderConfigOption{Name: rateLimitOptionName, Description: description}
+}
+
+const rateLimitOptionName = "rate_limit"
+
+// ParseRateLimit reads the rate_limit option of a profile, defaultRate when
+// not set.
+func ParseRateLimit(config ProviderConfig, defaultRate float64) (float64, error) {
+	if len(config[rateLimitOptionName]) == 0 {
+		return defaultRate, nil
+	}
+	rate, err := strconv.ParseFloat(config[rateLimitOptionName], 64)
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number", rateLimitOptionName)
+	}
+	return rate, nil
+}
+
+// Backoff computes growing delays between attempts. Delays are randomized so
+// that clients throttled together do not retry together.
+type Backoff struct {
+	Min      time.Duration
+	Max      time.Duration
+	attempts int
+}
+
+func NewBackoff(minDelay time.Duration, maxDelay time.Duration) *Backoff {
+	return &Backoff{Min: minDelay, Max: maxDelay}
+}
+
+// Next returns the delay before the next attempt: it doubles at each call up
+// to Max, randomized between half and all of it.
+func (backoff *Backoff) Next() time.Duration {
+	delay := backoff.Max
+	if backoff.attempts < 32 {
+		delay = min(backoff.Min<<backoff.attempts, backoff.Max)
+	}
+	backoff.attempts++
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// Reset makes the next delay Min again, after a successful attempt.
+func (backoff *Backoff) Reset() {
+	backoff.attempts = 0
+}
+
+// RateLimiter spaces calls so that at most rate calls are made per second.
+// Throttled halves the rate, down to rate/minRateDivisor, and Succeeded slowly
+// restores it.
+type RateLimiter struct {
+	mutex   sync.Mutex
+	maxRate float64
+	rate    float64
+	next    time.Time
+}
+
+const minRateDivisor = 16
+
+func NewRateLimiter(rate float64) *RateLimiter {
+	return &RateLimiter{maxRate: rate, rate: rate}
+}
+
+// Wait blocks until a call can be made or ctx ends.
+func (limiter *RateLimiter) Wait(ctx context.Context) error {
+	limiter.mutex.Lock()
+	now := time.Now()
+	at := limiter.next
+	if at.Before(now) {
+		at = now
+	}
+	limiter.next = at.Add(time.Duration(float64(time.Second) / limiter.rate))
+	limiter.mutex.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (limiter *RateLimiter) Throttled() {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.rate = max(limiter.rate/2, limiter.maxRate/minRateDivisor)
+}
+
+func (limiter *RateLimiter) Succeeded() {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.rate = min(limiter.rate*1.05, limiter.maxRate)
+}
+
+// RateLimitedTransport makes HTTP requests through a rate limiter, slowing
+// down when the server answers 429 Too Many Requests or 503 Service
+// Unavailable.
+type RateLimitedTransport struct {
+	Limiter *RateLimiter
+	Next    http.RoundTripper
+}
+
+// Decorate wraps an HTTP transport with the rate limiter, for SDK middleware
+// chains.
+func (limiter *RateLimiter) Decorate(next http.RoundTripper) http.RoundTripper {
+	return &RateLimitedTransport{Limiter: limiter, Next: next}
+}
+
+// NewRateLimitedClient returns an HTTP client making at most rate requests per
+// second.
+func NewRateLimitedClient(rate float64) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	return &http.Client{Transport: NewRateLimiter(rate).Decorate(transport)}
+}
+
+func (transport *RateLimitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if err := transport.Limiter.Wait(request.Context()); err != nil {
+		return nil, err
+	}
+	response, err := transport.Next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		transport.Limiter.Throttled()
+	default:
+		transport.Limiter.Succeeded()
+	}
+	return response, nil
+}
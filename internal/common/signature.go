@@ -0,0 +1,142 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// TrustedKey is an ed25519 public key whose signatures are accepted on plans.
+type TrustedKey struct {
+	Name string `json:"name"`
+	// PublicKey is the base64 encoded raw key.
+	PublicKey string `json:"public_key"`
+}
+
+// Signature is an ed25519 signature made with the private key of PublicKey.
+type Signature struct {
+	PublicKey string    `json:"public_key"`
+	Signature string    `json:"signature"`
+	SignedAt  time.Time `json:"signed_at"`
+}
+
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: %d bytes instead of %d", len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// GenerateSigningKey writes a new private key in PKCS #8 PEM format at path,
+// which must not exist, and returns its public key.
+func GenerateSigningKey(path string) (ed25519.PublicKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if err = pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return nil, err
+	}
+	return publicKey, file.Close()
+}
+
+// LoadSigningKey reads an ed25519 private key in PKCS #8 PEM format, like the
+// ones written by GenerateSigningKey or `openssl genpkey -algorithm ed25519`.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an ed25519 private key")
+	}
+	return privateKey, nil
+}
+
+func Sign(payload []byte, key ed25519.PrivateKey) Signature {
+	return Signature{
+		PublicKey: EncodePublicKey(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+		SignedAt:  time.Now().UTC(),
+	}
+}
+
+func (signature Signature) Verify(payload []byte) error {
+	key, err := ParsePublicKey(signature.PublicKey)
+	if err != nil {
+		return err
+	}
+	signed, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if !ed25519.Verify(key, payload, signed) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
+
+// TrustedKey returns the trusted key of a signature, nil if the key is not
+// trusted.
+func (config *Config) TrustedKey(publicKey string) *TrustedKey {
+	index := slices.IndexFunc(config.TrustedKeys, func(key TrustedKey) bool {
+		return key.PublicKey == publicKey
+	})
+	if index < 0 {
+		return nil
+	}
+	return &config.TrustedKeys[index]
+}
+
+// VerifySignatures returns the names of the trusted keys having signed
+// payload. A signature of a trusted key which does not match payload is an
+// error, as the payload was modified after being signed.
+func (config *Config) VerifySignatures(payload []byte, signatures []Signature) ([]string, error) {
+	var signers []string
+	for _, signature := range signatures {
+		key := config.TrustedKey(signature.PublicKey)
+		if key == nil {
+			continue
+		}
+		if err := signature.Verify(payload); err != nil {
+			return nil, fmt.Errorf("signature of %s: %w", key.Name, err)
+		}
+		if !slices.Contains(signers, key.Name) {
+			signers = append(signers, key.Name)
+		}
+	}
+	return signers, nil
+}
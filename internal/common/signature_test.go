@@ -0,0 +1,65 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"slices"
+	"testing"
+)
+
+func newTestKey(t *testing.T) (ed25519.PrivateKey, string) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privateKey, EncodePublicKey(publicKey)
+}
+
+func TestVerifySignatures(t *testing.T) {
+	alice, alicePublic := newTestKey(t)
+	bob, bobPublic := newTestKey(t)
+	mallory, _ := newTestKey(t)
+	config := Config{TrustedKeys: []TrustedKey{
+		{Name: "alice", PublicKey: alicePublic},
+		{Name: "bob", PublicKey: bobPublic},
+	}}
+	payload := []byte(`{"targets":[]}`)
+	signatures := []Signature{Sign(payload, alice), Sign(payload, bob), Sign(payload, mallory), Sign(payload, alice)}
+
+	signers, err := config.VerifySignatures(payload, signatures)
+	if err != nil {
+		t.Fatalf("valid signatures rejected: %s", err)
+	}
+	if !slices.Equal(signers, []string{"alice", "bob"}) {
+		t.Errorf("signers are %v, expected alice and bob once each", signers)
+	}
+
+	if _, err = config.VerifySignatures([]byte(`{"targets":[{}]}`), signatures); err == nil {
+		t.Error("signatures of a modified payload accepted")
+	}
+
+	signers, err = config.VerifySignatures([]byte(`{"targets":[{}]}`), []Signature{Sign(payload, mallory)})
+	if err != nil || len(signers) != 0 {
+		t.Errorf("untrusted signature counted: %v, %v", signers, err)
+	}
+}
+
+func TestSignatureVerify(t *testing.T) {
+	key, _ := newTestKey(t)
+	signature := Sign([]byte("payload"), key)
+	if err := signature.Verify([]byte("payload")); err != nil {
+		t.Errorf("valid signature rejected: %s", err)
+	}
+	_, otherPublic := newTestKey(t)
+	forged := signature
+	forged.PublicKey = otherPublic
+	if err := forged.Verify([]byte("payload")); err == nil {
+		t.Error("signature accepted with another key")
+	}
+	forged = signature
+	forged.Signature = "not base64"
+	if err := forged.Verify([]byte("payload")); err == nil {
+		t.Error("invalid signature encoding accepted")
+	}
+}
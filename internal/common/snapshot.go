@@ -1,12 +1,12 @@
 package common
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path"
+	"io"
 	"strings"
 )
 
@@ -28,54 +28,80 @@ type SnapshotData struct {
 }
 
 type Diff struct {
-	Retained Objects
-	Created  Objects
-	Deleted  Objects
+	Retained Objects `json:"retained"`
+	Created  Objects `json:"created"`
+	Deleted  Objects `json:"deleted"`
 }
 
+// SnapshotVersion is the version of snapshots written by this frieza.
+// Version 2 added tag, age and pattern filters, which older versions would
+// ignore: they refuse such snapshots instead of cleaning too much.
 func SnapshotVersion() int {
-	return 0
+	return 2
 }
 
 func ReadObjects(ctx context.Context, provider *Provider, filters *ResourceFilterEnvelope) (Objects, error) {
-	objects := make(Objects)
-	for _, typeName := range (*provider).Types() {
-		if filters != nil && !filters.Select(typeName) {
-			continue
-		}
+	target := NewInventoryTarget((*provider).Name(), provider, filters)
+	objects, err := NewInventory(DefaultParallelism).Read(ctx, []InventoryTarget{target})
+	return objects[0], err
+}
 
-		var err error
-		objects[typeName], err = (*provider).ReadObjects(ctx, typeName)
-		if err != nil {
-			return objects, err
+func ReadNonEmptyObjects(ctx context.Context, provider *Provider, nonEmpy Objects) (Objects, error) {
+	target := InventoryTarget{Name: (*provider).Name(), Provider: provider}
+	for _, typeName := range (*provider).Types() {
+		if len(nonEmpy[typeName]) > 0 {
+			target.Types = append(target.Types, typeName)
 		}
 	}
-	return objects, nil
+	objects, err := NewInventory(DefaultParallelism).Read(ctx, []InventoryTarget{target})
+	return objects[0], err
 }
 
-func ReadNonEmptyObjects(ctx context.Context, provider *Provider, nonEmpy Objects) (Objects, error) {
-	objects := make(Objects)
-	for _, typeName := range (*provider).Types() {
-		if len(nonEmpy[typeName]) == 0 {
+// ReadRemainingObjects returns, for each type successfully read, the targeted
+// objects which still exist. Objects are checked by ID when the provider is an
+// ExistenceChecker, their types are read in full otherwise.
+func ReadRemainingObjects(ctx context.Context, provider *Provider, targets Objects) (Objects, error) {
+	remaining := make(Objects)
+	var errs []error
+	unchecked := make(Objects)
+	checker, _ := (*provider).(ExistenceChecker)
+	for typeName, objects := range targets {
+		if len(objects) == 0 {
+			continue
+		}
+		if checker == nil {
+			unchecked[typeName] = objects
 			continue
 		}
-		var err error
-		objects[typeName], err = (*provider).ReadObjects(ctx, typeName)
-		if err != nil {
-			return objects, err
+		existing, err := checker.ExistingObjects(ctx, typeName, objects)
+		switch {
+		case errors.Is(err, errors.ErrUnsupported):
+			unchecked[typeName] = objects
+			continue
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", (*provider).Name(), err))
+			continue
 		}
+		remaining[typeName] = keepObjects(objects, existing)
+	}
+	current, err := ReadNonEmptyObjects(ctx, provider, unchecked)
+	errs = append(errs, err)
+	for typeName, currentObjects := range current {
+		remaining[typeName] = keepObjects(unchecked[typeName], currentObjects)
 	}
-	return objects, nil
+	return remaining, errors.Join(errs...)
 }
 
-func DeleteObjects(ctx context.Context, provider *Provider, objects Objects) {
-	for _, typeName := range (*provider).Types() {
-		objectList := objects[typeName]
-		if len(objectList) == 0 {
-			continue
+// keepObjects returns the objects of targets found in current.
+func keepObjects(targets []Object, current []Object) []Object {
+	currentIds := objects2Map(current)
+	kept := make([]Object, 0)
+	for _, object := range targets {
+		if _, ok := currentIds[object.Id]; ok {
+			kept = append(kept, object)
 		}
-		(*provider).DeleteObjects(ctx, typeName, objectList)
 	}
+	return kept
 }
 
 func NewDiff() *Diff {
@@ -86,36 +112,32 @@ func NewDiff() *Diff {
 	}
 }
 
-func objects2Map(objectIds []Object) map[string]bool {
-	out := make(map[string]bool)
-	for _, objectId := range objectIds {
-		out[objectId] = true
+func objects2Map(objects []Object) map[string]Object {
+	out := make(map[string]Object)
+	for _, object := range objects {
+		out[object.Id] = object
 	}
 	return out
 }
 
+// Build compares objects a with newer objects b, keeping the order of
+// objects.
 func (diff *Diff) Build(a *Objects, b *Objects) {
-	allTypes := make(map[string]bool)
-	for objectTypeA := range *a {
-		allTypes[objectTypeA] = true
-	}
-	for objectTypeB := range *b {
-		allTypes[objectTypeB] = true
-	}
-
-	for objectType := range allTypes {
-		aFlat := objects2Map((*a)[objectType])
+	for objectType, objectsA := range *a {
 		bFlat := objects2Map((*b)[objectType])
-		for idA := range aFlat {
-			if bFlat[idA] {
-				diff.Retained[objectType] = append(diff.Retained[objectType], idA)
+		for _, objectA := range objectsA {
+			if _, ok := bFlat[objectA.Id]; ok {
+				diff.Retained[objectType] = append(diff.Retained[objectType], objectA)
 			} else {
-				diff.Deleted[objectType] = append(diff.Deleted[objectType], idA)
+				diff.Deleted[objectType] = append(diff.Deleted[objectType], objectA)
 			}
 		}
-		for idB := range bFlat {
-			if !aFlat[idB] {
-				diff.Created[objectType] = append(diff.Created[objectType], idB)
+	}
+	for objectType, objectsB := range *b {
+		aFlat := objects2Map((*a)[objectType])
+		for _, objectB := range objectsB {
+			if _, ok := aFlat[objectB.Id]; !ok {
+				diff.Created[objectType] = append(diff.Created[objectType], objectB)
 			}
 		}
 	}
@@ -131,59 +153,98 @@ func ObjectsCount(objects *Objects) int {
 
 func ObjectsPrint(provider *Provider, objects *Objects) string {
 	var outBuilder strings.Builder
-	for objectType, objectIds := range *objects {
-		if len(objectIds) == 0 {
+	for objectType, objectList := range *objects {
+		if len(objectList) == 0 {
 			continue
 		}
 		outBuilder.WriteString(objectType + ":\n")
-		for _, objectId := range objectIds {
-			fmt.Fprintf(&outBuilder, "  - %s\n", (*provider).StringObject(objectId, objectType))
+		for _, object := range objectList {
+			fmt.Fprintf(&outBuilder, "  - %s\n", (*provider).StringObject(object, objectType))
 		}
 	}
 	return outBuilder.String()
 }
 
+// Write saves the snapshot in its store, with the snapshot_encoding of its
+// configuration.
 func (snapshot *Snapshot) Write() error {
-	if err := os.MkdirAll(snapshot.Config.SnapshotFolderPath, os.ModePerm); err != nil {
+	store, err := OpenSnapshotStore(snapshot.Config)
+	if err != nil {
 		return err
 	}
+	if snapshot.Config.SnapshotEncoding == SnapshotEncodingCompact {
+		return snapshot.writeCompact(context.Background(), store)
+	}
 	json_bytes, err := json.MarshalIndent(snapshot, "", "  ")
 	if err != nil {
 		return err
 	}
-	if err = os.WriteFile(snapshot.Path(), json_bytes, 0o700); err != nil {
-		return err
-	}
-	return nil
+	return store.Write(context.Background(), snapshot.Name, json_bytes)
 }
 
-func (snapshot *Snapshot) Path() string {
-	return path.Join(snapshot.Config.SnapshotFolderPath, snapshot.Name+".json")
+// SnapshotLoad reads a snapshot in any encoding.
+func SnapshotLoad(name string, config *Config) (*Snapshot, error) {
+	store, err := OpenSnapshotStore(config)
+	if err != nil {
+		return nil, err
+	}
+	file, err := openSnapshot(context.Background(), store, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	if isCompactSnapshot(reader) {
+		return loadCompactSnapshot(reader, config)
+	}
+	return decodeSnapshot(reader, name, config)
 }
 
-func SnapshotLoad(name string, config *Config) (*Snapshot, error) {
+// decodeSnapshot reads a snapshot encoded as one JSON document.
+func decodeSnapshot(reader io.Reader, name string, config *Config) (*Snapshot, error) {
 	snapshot := &Snapshot{
 		Name:   name,
 		Config: config,
 	}
-	snapshot_json, err := os.ReadFile(snapshot.Path())
+	snapshot_json, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
-	if err := json.Unmarshal(snapshot_json, &snapshot); err != nil {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(snapshot_json, &header); err != nil {
 		return nil, err
 	}
-	if snapshot.Version > SnapshotVersion() {
+	if header.Version > SnapshotVersion() {
 		return nil, errors.New("snapshot version not supported, please upgrade frieza")
 	}
+	if header.Version == 0 {
+		return migrateSnapshotV0(snapshot_json, config)
+	}
+	if err := json.Unmarshal(snapshot_json, &snapshot); err != nil {
+		return nil, err
+	}
 	return snapshot, nil
 }
 
+// SnapshotList returns the names of the snapshots of the configured store.
+func SnapshotList(config *Config) ([]string, error) {
+	store, err := OpenSnapshotStore(config)
+	if err != nil {
+		return nil, err
+	}
+	return store.List(context.Background())
+}
+
 func (snapshot Snapshot) String() string {
 	var outBuilder strings.Builder
 
 	fmt.Fprintf(&outBuilder, "name: %v\n", snapshot.Name)
 	fmt.Fprintf(&outBuilder, "date: %v\n", snapshot.Date)
+	if snapshot.Filters != nil {
+		fmt.Fprintf(&outBuilder, "filters: %v\n", snapshot.Filters)
+	}
 	outBuilder.WriteString("profiles:\n")
 
 	for _, data := range snapshot.Data {
@@ -197,5 +258,9 @@ func (snapshot Snapshot) String() string {
 }
 
 func (snapshot Snapshot) Delete() error {
-	return os.Remove(snapshot.Path())
+	store, err := OpenSnapshotStore(snapshot.Config)
+	if err != nil {
+		return err
+	}
+	return store.Delete(context.Background(), snapshot.Name)
 }
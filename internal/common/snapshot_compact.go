@@ -0,0 +1,267 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Snapshot encodings, chosen by the snapshot_encoding option.
+const (
+	// SnapshotEncodingJson writes snapshots as one indented JSON document.
+	SnapshotEncodingJson = "json"
+	// SnapshotEncodingCompact writes snapshots as gzip compressed JSON lines,
+	// one line per object, so that they are written and read as a stream.
+	SnapshotEncodingCompact = "compact"
+)
+
+// snapshotRecord is a line of a compact snapshot after its header: a record
+// with a profile starts the data of a profile and provider, the following
+// records with an object belong to it.
+type snapshotRecord struct {
+	Profile  string     `json:"profile,omitempty"`
+	Provider string     `json:"provider,omitempty"`
+	Type     ObjectType `json:"type,omitempty"`
+	Object   *Object    `json:"object,omitempty"`
+}
+
+type snapshotHeader struct {
+	Version int                     `json:"version"`
+	Name    string                  `json:"name"`
+	Date    string                  `json:"date"`
+	Filters *ResourceFilterEnvelope `json:"filters"`
+}
+
+// SnapshotStreamReader can be implemented by stores able to read snapshots
+// without holding them in memory.
+type SnapshotStreamReader interface {
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// SnapshotStreamWriter can be implemented by stores able to write snapshots
+// without holding them in memory.
+type SnapshotStreamWriter interface {
+	Create(ctx context.Context, name string) (SnapshotWriter, error)
+}
+
+// SnapshotWriter writes a new version of a snapshot. Close replaces the
+// snapshot with the written content, Abort discards it and leaves the
+// snapshot unchanged.
+type SnapshotWriter interface {
+	io.Writer
+	Close() error
+	Abort() error
+}
+
+func openSnapshot(ctx context.Context, store SnapshotStore, name string) (io.ReadCloser, error) {
+	if reader, ok := store.(SnapshotStreamReader); ok {
+		return reader.Open(ctx, name)
+	}
+	data, err := store.Read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func createSnapshot(ctx context.Context, store SnapshotStore, name string) (SnapshotWriter, error) {
+	if writer, ok := store.(SnapshotStreamWriter); ok {
+		return writer.Create(ctx, name)
+	}
+	return &bufferedSnapshotWriter{ctx: ctx, store: store, name: name}, nil
+}
+
+// bufferedSnapshotWriter writes a snapshot to a store when closed.
+type bufferedSnapshotWriter struct {
+	bytes.Buffer
+	ctx   context.Context
+	store SnapshotStore
+	name  string
+}
+
+func (writer *bufferedSnapshotWriter) Close() error {
+	return writer.store.Write(writer.ctx, writer.name, writer.Bytes())
+}
+
+func (writer *bufferedSnapshotWriter) Abort() error {
+	writer.Reset()
+	return nil
+}
+
+// isCompactSnapshot reports whether a snapshot starts with the gzip magic
+// number, JSON snapshots starting with a brace.
+func isCompactSnapshot(reader *bufio.Reader) bool {
+	magic, err := reader.Peek(2)
+	return err == nil && magic[0] == 0x1f && magic[1] == 0x8b
+}
+
+func (snapshot *Snapshot) writeCompact(ctx context.Context, store SnapshotStore) error {
+	file, err := createSnapshot(ctx, store, snapshot.Name)
+	if err != nil {
+		return err
+	}
+	compressed := gzip.NewWriter(file)
+	lines := bufio.NewWriter(compressed)
+	encoder := json.NewEncoder(lines)
+	err = encoder.Encode(snapshotHeader{
+		Version: snapshot.Version,
+		Name:    snapshot.Name,
+		Date:    snapshot.Date,
+		Filters: snapshot.Filters,
+	})
+	for _, data := range snapshot.Data {
+		if err != nil {
+			break
+		}
+		err = encoder.Encode(snapshotRecord{Profile: data.Profile, Provider: data.Provider})
+		for typeName, objects := range data.Objects {
+			for i := 0; i < len(objects) && err == nil; i++ {
+				err = encoder.Encode(snapshotRecord{Type: typeName, Object: &objects[i]})
+			}
+		}
+	}
+	if err == nil {
+		err = lines.Flush()
+	}
+	if err == nil {
+		err = compressed.Close()
+	}
+	// A partial snapshot must never replace a complete one: clean would
+	// delete every object missing from it.
+	if err != nil {
+		return errors.Join(err, file.Abort())
+	}
+	return file.Close()
+}
+
+// scanCompactSnapshot decodes a compact snapshot, calling onData when the data
+// of a profile and provider starts and onObject for each of its objects.
+func scanCompactSnapshot(reader io.Reader, onData func(profile string, provider string), onObject func(typeName ObjectType, object Object)) (*snapshotHeader, error) {
+	uncompressed, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer uncompressed.Close()
+	decoder := json.NewDecoder(uncompressed)
+	var header snapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("invalid snapshot header: %w", err)
+	}
+	if header.Version > SnapshotVersion() {
+		return nil, errors.New("snapshot version not supported, please upgrade frieza")
+	}
+	started := false
+	for {
+		var record snapshotRecord
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return &header, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot: %w", err)
+		}
+		switch {
+		case len(record.Profile) > 0:
+			started = true
+			onData(record.Profile, record.Provider)
+		case record.Object != nil && started:
+			onObject(record.Type, *record.Object)
+		default:
+			return nil, errors.New("invalid snapshot: object outside of profile data")
+		}
+	}
+}
+
+func loadCompactSnapshot(reader io.Reader, config *Config) (*Snapshot, error) {
+	var data []SnapshotData
+	header, err := scanCompactSnapshot(reader,
+		func(profile string, provider string) {
+			data = append(data, SnapshotData{Profile: profile, Provider: provider, Objects: make(Objects)})
+		},
+		func(typeName ObjectType, object Object) {
+			objects := data[len(data)-1].Objects
+			objects[typeName] = append(objects[typeName], object)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		Version: header.Version,
+		Name:    header.Name,
+		Date:    header.Date,
+		Filters: header.Filters,
+		Data:    data,
+		Config:  config,
+	}, nil
+}
+
+// SnapshotIndex is a snapshot whose objects are only kept by identifier, to
+// compare large snapshots with current objects.
+type SnapshotIndex struct {
+	Name    string
+	Date    string
+	Filters *ResourceFilterEnvelope
+	Data    []SnapshotDataIndex
+}
+
+type SnapshotDataIndex struct {
+	Profile  string
+	Provider string
+	Ids      ObjectIdSets
+}
+
+// SnapshotIndexLoad reads the object identifiers of a snapshot. Compact
+// snapshots are read as a stream, their objects are never all in memory.
+func SnapshotIndexLoad(name string, config *Config) (*SnapshotIndex, error) {
+	store, err := OpenSnapshotStore(config)
+	if err != nil {
+		return nil, err
+	}
+	file, err := openSnapshot(context.Background(), store, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	if !isCompactSnapshot(reader) {
+		snapshot, err := decodeSnapshot(reader, name, config)
+		if err != nil {
+			return nil, err
+		}
+		index := &SnapshotIndex{Name: snapshot.Name, Date: snapshot.Date, Filters: snapshot.Filters}
+		for _, data := range snapshot.Data {
+			index.Data = append(index.Data, SnapshotDataIndex{
+				Profile:  data.Profile,
+				Provider: data.Provider,
+				Ids:      NewObjectIdSets(data.Objects),
+			})
+		}
+		return index, nil
+	}
+	index := &SnapshotIndex{}
+	header, err := scanCompactSnapshot(reader,
+		func(profile string, provider string) {
+			index.Data = append(index.Data, SnapshotDataIndex{Profile: profile, Provider: provider, Ids: make(ObjectIdSets)})
+		},
+		func(typeName ObjectType, object Object) {
+			ids := index.Data[len(index.Data)-1].Ids
+			if ids[typeName] == nil {
+				ids[typeName] = make(IdSet)
+			}
+			ids[typeName][object.Id] = struct{}{}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	index.Name = header.Name
+	index.Date = header.Date
+	index.Filters = header.Filters
+	return index, nil
+}
@@ -0,0 +1,42 @@
+package common
+
+// SnapshotDataDiff compares the objects of a profile and provider between
+// two snapshots.
+type SnapshotDataDiff struct {
+	Profile  string `json:"profile"`
+	Provider string `json:"provider"`
+	*Diff
+}
+
+// SnapshotDiff compares snapshot a with newer snapshot b, per profile and
+// provider. Data only present in a is deleted, data only in b is created.
+func SnapshotDiff(a *Snapshot, b *Snapshot) []SnapshotDataDiff {
+	var diffs []SnapshotDataDiff
+	for _, dataA := range a.Data {
+		diff := NewDiff()
+		objectsB := make(Objects)
+		if dataB := b.findData(dataA.Profile, dataA.Provider); dataB != nil {
+			objectsB = dataB.Objects
+		}
+		diff.Build(&dataA.Objects, &objectsB)
+		diffs = append(diffs, SnapshotDataDiff{Profile: dataA.Profile, Provider: dataA.Provider, Diff: diff})
+	}
+	for _, dataB := range b.Data {
+		if a.findData(dataB.Profile, dataB.Provider) != nil {
+			continue
+		}
+		diff := NewDiff()
+		diff.Build(&Objects{}, &dataB.Objects)
+		diffs = append(diffs, SnapshotDataDiff{Profile: dataB.Profile, Provider: dataB.Provider, Diff: diff})
+	}
+	return diffs
+}
+
+func (snapshot *Snapshot) findData(profile string, provider string) *SnapshotData {
+	for i := range snapshot.Data {
+		if snapshot.Data[i].Profile == profile && snapshot.Data[i].Provider == provider {
+			return &snapshot.Data[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package common
+
+import "slices"
+
+// Select returns the objects of data selected by filter, which can be nil,
+// and whose id is one of ids when ids is not empty.
+func (data *SnapshotData) Select(filter *ResourceFilterEnvelope, ids []string) Objects {
+	selected := make(Objects)
+	for typeName, objects := range data.Objects {
+		if filter != nil && !filter.Select(typeName) {
+			continue
+		}
+		for _, object := range objects {
+			if len(ids) > 0 && !slices.Contains(ids, object.Id) {
+				continue
+			}
+			if filter != nil && !filter.SelectObject(typeName, object) {
+				continue
+			}
+			selected[typeName] = append(selected[typeName], object)
+		}
+	}
+	return selected
+}
+
+// Forget removes objects from data so that they are no longer part of the
+// snapshot, and returns how many were removed.
+func (data *SnapshotData) Forget(objects Objects) int {
+	count := 0
+	for typeName, forgotten := range objects {
+		ids := objects2Map(forgotten)
+		remaining := slices.DeleteFunc(data.Objects[typeName], func(object Object) bool {
+			_, found := ids[object.Id]
+			return found
+		})
+		count += len(data.Objects[typeName]) - len(remaining)
+		data.Objects[typeName] = remaining
+	}
+	return count
+}
+
+// Vanished returns the deleted objects of a diff against result which really
+// no longer exist: objects of types which were not read and objects skipped by
+// filters are still there.
+func Vanished(deleted Objects, result InventoryResult) Objects {
+	vanished := make(Objects)
+	for typeName, objects := range deleted {
+		if _, read := result.Objects[typeName]; !read {
+			continue
+		}
+		skipped := make(map[string]bool)
+		for _, object := range result.Skipped[typeName] {
+			skipped[object.Object.Id] = true
+		}
+		for _, object := range objects {
+			if !skipped[object.Id] {
+				vanished[typeName] = append(vanished[typeName], object)
+			}
+		}
+	}
+	return vanished
+}
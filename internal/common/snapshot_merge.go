@@ -0,0 +1,105 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrIncompatibleFilters is returned when merging snapshots taken with
+// different filters.
+var ErrIncompatibleFilters = errors.New("snapshots have incompatible filters")
+
+// SnapshotMerge combines snapshots in a new one named name. Data of the same
+// profile and provider are merged, objects being deduplicated by id. When
+// filters differ, merging fails with ErrIncompatibleFilters unless force is
+// set: the merged snapshot then only selects what all filters select, so that
+// clean never considers objects one of the snapshots did not look at.
+func SnapshotMerge(name string, snapshots []*Snapshot, force bool, config *Config) (*Snapshot, error) {
+	merged := &Snapshot{
+		Version: SnapshotVersion(),
+		Name:    name,
+		Date:    time.Now().UTC().String(),
+		Config:  config,
+	}
+	if len(snapshots) == 0 {
+		return merged, nil
+	}
+	filters, err := mergeFilters(snapshots, force)
+	if err != nil {
+		return nil, err
+	}
+	merged.Filters = filters
+	for _, snapshot := range snapshots {
+		for _, data := range snapshot.Data {
+			mergedData := merged.findData(data.Profile, data.Provider)
+			if mergedData == nil {
+				merged.Data = append(merged.Data, SnapshotData{
+					Profile:  data.Profile,
+					Provider: data.Provider,
+					Objects:  make(Objects),
+				})
+				mergedData = &merged.Data[len(merged.Data)-1]
+			}
+			mergeObjects(mergedData.Objects, data.Objects)
+		}
+	}
+	return merged, nil
+}
+
+// mergeObjects adds to objects the ones of added not already there.
+func mergeObjects(objects Objects, added Objects) {
+	for typeName, addedObjects := range added {
+		ids := objects2Map(objects[typeName])
+		if objects[typeName] == nil {
+			objects[typeName] = make([]Object, 0, len(addedObjects))
+		}
+		for _, object := range addedObjects {
+			if _, found := ids[object.Id]; found {
+				continue
+			}
+			ids[object.Id] = object
+			objects[typeName] = append(objects[typeName], object)
+		}
+	}
+}
+
+func mergeFilters(snapshots []*Snapshot, force bool) (*ResourceFilterEnvelope, error) {
+	filters := snapshots[0].Filters
+	reference, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots[1:] {
+		other, err := json.Marshal(snapshot.Filters)
+		if err != nil {
+			return nil, err
+		}
+		if string(other) == string(reference) {
+			continue
+		}
+		if !force {
+			return nil, fmt.Errorf("%w: %s (%s) and %s (%s)",
+				ErrIncompatibleFilters,
+				snapshots[0].Name, filtersString(snapshots[0].Filters),
+				snapshot.Name, filtersString(snapshot.Filters),
+			)
+		}
+		switch {
+		case snapshot.Filters == nil:
+		case filters == nil:
+			filters = snapshot.Filters
+		default:
+			filters = filters.With(snapshot.Filters)
+		}
+	}
+	return filters, nil
+}
+
+func filtersString(filters *ResourceFilterEnvelope) string {
+	if filters == nil {
+		return "no filter"
+	}
+	return filters.String()
+}
@@ -0,0 +1,47 @@
+package common
+
+import "encoding/json"
+
+// snapshotV0 is the snapshot layout used before objects carried metadata:
+// objects were stored as bare identifiers.
+type snapshotV0 struct {
+	Name    string                  `json:"name"`
+	Date    string                  `json:"date"`
+	Data    []snapshotDataV0        `json:"data"`
+	Filters *ResourceFilterEnvelope `json:"filters"`
+}
+
+type snapshotDataV0 struct {
+	Profile  string                  `json:"profile"`
+	Provider string                  `json:"provider"`
+	Objects  map[ObjectType][]string `json:"objects"`
+}
+
+func migrateSnapshotV0(snapshot_json []byte, config *Config) (*Snapshot, error) {
+	var legacy snapshotV0
+	if err := json.Unmarshal(snapshot_json, &legacy); err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{
+		Version: SnapshotVersion(),
+		Name:    legacy.Name,
+		Date:    legacy.Date,
+		Filters: legacy.Filters,
+		Config:  config,
+	}
+	for _, legacyData := range legacy.Data {
+		objects := make(Objects)
+		for objectType, ids := range legacyData.Objects {
+			objects[objectType] = make([]Object, 0, len(ids))
+			for _, id := range ids {
+				objects[objectType] = append(objects[objectType], NewObject(id))
+			}
+		}
+		snapshot.Data = append(snapshot.Data, SnapshotData{
+			Profile:  legacyData.Profile,
+			Provider: legacyData.Provider,
+			Objects:  objects,
+		})
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SnapshotStore keeps serialized snapshots by name. Reading or deleting a
+// missing snapshot returns an error wrapping fs.ErrNotExist.
+type SnapshotStore interface {
+	Read(ctx context.Context, name string) ([]byte, error)
+	Write(ctx context.Context, name string, data []byte) error
+	Delete(ctx context.Context, name string) error
+	List(ctx context.Context) ([]string, error)
+}
+
+// SnapshotStoreOpener builds a store from its location, like
+// "s3://bucket/prefix?profile=myProfile".
+type SnapshotStoreOpener func(location *url.URL, config *Config) (SnapshotStore, error)
+
+var (
+	snapshotStoresMutex sync.RWMutex
+	snapshotStores      = make(map[string]SnapshotStoreOpener)
+)
+
+// RegisterSnapshotStore makes a store available for locations using scheme.
+// It is meant to be called from init functions and panics if the scheme is
+// already registered.
+func RegisterSnapshotStore(scheme string, opener SnapshotStoreOpener) {
+	snapshotStoresMutex.Lock()
+	defer snapshotStoresMutex.Unlock()
+	if _, exists := snapshotStores[scheme]; exists {
+		panic(fmt.Sprintf("snapshot store %s registered twice", scheme))
+	}
+	snapshotStores[scheme] = opener
+}
+
+// OpenSnapshotStore returns the store configured by snapshot_store, snapshots
+// are kept in SnapshotFolderPath when it is not set.
+func OpenSnapshotStore(config *Config) (SnapshotStore, error) {
+	if len(config.SnapshotStore) == 0 || config.SnapshotStore == "local" {
+		return NewDirectorySnapshotStore(config.SnapshotFolderPath), nil
+	}
+	location, err := url.Parse(config.SnapshotStore)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot store: %w", err)
+	}
+	snapshotStoresMutex.RLock()
+	opener, found := snapshotStores[location.Scheme]
+	snapshotStoresMutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown snapshot store %q", config.SnapshotStore)
+	}
+	return opener(location, config)
+}
+
+// DirectorySnapshotStore keeps snapshots as JSON files in a local folder.
+type DirectorySnapshotStore struct {
+	Path string
+}
+
+func NewDirectorySnapshotStore(folderPath string) *DirectorySnapshotStore {
+	return &DirectorySnapshotStore{Path: folderPath}
+}
+
+func (store *DirectorySnapshotStore) filePath(name string) string {
+	return path.Join(store.Path, name+".json")
+}
+
+func (store *DirectorySnapshotStore) Read(ctx context.Context, name string) ([]byte, error) {
+	return os.ReadFile(store.filePath(name))
+}
+
+func (store *DirectorySnapshotStore) Write(ctx context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(store.Path, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(store.filePath(name), data, 0o700)
+}
+
+func (store *DirectorySnapshotStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(store.filePath(name))
+}
+
+// Create writes the snapshot to a temporary file, renamed when closed so that
+// readers never see a partial snapshot.
+func (store *DirectorySnapshotStore) Create(ctx context.Context, name string) (SnapshotWriter, error) {
+	if err := os.MkdirAll(store.Path, os.ModePerm); err != nil {
+		return nil, err
+	}
+	filePath := store.filePath(name)
+	file, err := os.OpenFile(filePath+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	return &renamingFile{File: file, path: filePath}, nil
+}
+
+type renamingFile struct {
+	*os.File
+	path string
+}
+
+func (file *renamingFile) Close() error {
+	if err := errors.Join(file.Sync(), file.File.Close()); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), file.path)
+}
+
+// Abort removes the temporary file, keeping the previous snapshot.
+func (file *renamingFile) Abort() error {
+	return errors.Join(file.File.Close(), os.Remove(file.Name()))
+}
+
+func (store *DirectorySnapshotStore) Delete(ctx context.Context, name string) error {
+	return os.Remove(store.filePath(name))
+}
+
+func (store *DirectorySnapshotStore) List(ctx context.Context) ([]string, error) {
+	files, err := os.ReadDir(store.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(file.Name(), ".json"))
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestDirectorySnapshotStoreCreate(t *testing.T) {
+	ctx := context.Background()
+	store := NewDirectorySnapshotStore(t.TempDir())
+	if err := store.Write(ctx, "snap", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	writer, err := store.Create(ctx, "snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = writer.Write([]byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Read(ctx, "snap"); err != nil || string(data) != "old" {
+		t.Errorf("snapshot changed before Close: %q, %v", data, err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Read(ctx, "snap"); err != nil || string(data) != "new" {
+		t.Errorf("snapshot not replaced by Close: %q, %v", data, err)
+	}
+
+	writer, err = store.Create(ctx, "snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = writer.Write([]byte("partial")); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Read(ctx, "snap"); err != nil || string(data) != "new" {
+		t.Errorf("snapshot changed by Abort: %q, %v", data, err)
+	}
+	if _, err = os.Stat(store.filePath("snap") + ".tmp"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("temporary file kept after Abort: %v", err)
+	}
+	names, err := store.List(ctx)
+	if err != nil || !slices.Equal(names, []string{"snap"}) {
+		t.Errorf("snapshots listed after Abort: %v, %v", names, err)
+	}
+}
+
+func TestDirectorySnapshotStoreCreateAbortNew(t *testing.T) {
+	ctx := context.Background()
+	store := NewDirectorySnapshotStore(t.TempDir())
+	writer, err := store.Create(ctx, "snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Read(ctx, "snap"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("aborted snapshot exists: %v", err)
+	}
+}
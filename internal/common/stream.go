@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"iter"
+)
+
+// StreamObjects yields the objects of a type, as they are read when the
+// provider is an ObjectStreamer or after reading them all otherwise.
+func StreamObjects(ctx context.Context, provider *Provider, typeName ObjectType) iter.Seq2[Object, error] {
+	if streamer, ok := (*provider).(ObjectStreamer); ok {
+		return streamer.StreamObjects(ctx, typeName)
+	}
+	return ObjectsSeq((*provider).ReadObjects(ctx, typeName))
+}
+
+// ObjectsSeq yields objects already read, or only err when it is not nil.
+func ObjectsSeq(objects []Object, err error) iter.Seq2[Object, error] {
+	return func(yield func(Object, error) bool) {
+		if err != nil {
+			yield(Object{}, err)
+			return
+		}
+		for _, object := range objects {
+			if !yield(object, nil) {
+				return
+			}
+		}
+	}
+}
+
+// CollectObjects reads all objects of a stream, for providers implementing
+// ReadObjects with StreamObjects.
+func CollectObjects(objects iter.Seq2[Object, error]) ([]Object, error) {
+	collected := make([]Object, 0)
+	for object, err := range objects {
+		if err != nil {
+			return nil, err
+		}
+		collected = append(collected, object)
+	}
+	return collected, nil
+}
+
+// IdSet is a set of object identifiers, much smaller than the objects
+// themselves.
+type IdSet = map[string]struct{}
+
+// ObjectIdSets indexes the identifiers of objects by type.
+type ObjectIdSets = map[ObjectType]IdSet
+
+// NewObjectIdSets returns the identifiers of objects.
+func NewObjectIdSets(objects Objects) ObjectIdSets {
+	sets := make(ObjectIdSets, len(objects))
+	for typeName, typeObjects := range objects {
+		set := make(IdSet, len(typeObjects))
+		for _, object := range typeObjects {
+			set[object.Id] = struct{}{}
+		}
+		sets[typeName] = set
+	}
+	return sets
+}
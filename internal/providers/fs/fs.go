@@ -4,12 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
 	"path"
+	"strconv"
+	"syscall"
 
 	. "github.com/outscale/frieza/internal/common"
-	"github.com/teris-io/cli"
 )
 
 const Name = "fs"
@@ -44,9 +45,22 @@ func Types() []ObjectType {
 	return objectTypes
 }
 
-func Cli() (string, cli.Command) {
-	return Name, cli.NewCommand(Name, "create new file system profile").
-		WithOption(cli.NewOption("path", "folder path"))
+func Dependencies() map[ObjectType][]ObjectType {
+	return map[ObjectType][]ObjectType{
+		typeFolder: {typeFile},
+	}
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:        Name,
+		Description: "file system",
+		New:         ProviderConstructor(New),
+		Types:       Types,
+		Config: []ProviderConfigOption{
+			{Name: "path", Description: "folder path"},
+		},
+	})
 }
 
 func (provider *FileSystem) Name() string {
@@ -57,6 +71,10 @@ func (provider *FileSystem) Types() []ObjectType {
 	return Types()
 }
 
+func (provider *FileSystem) Dependencies() map[ObjectType][]ObjectType {
+	return Dependencies()
+}
+
 func (provider *FileSystem) AuthTest(ctx context.Context) error {
 	// Will test if we can access the folder
 	if _, err := os.ReadDir(provider.Path); err != nil {
@@ -75,26 +93,58 @@ func (provider *FileSystem) ReadObjects(ctx context.Context, typeName string) ([
 	return []Object{}, nil
 }
 
-func (provider *FileSystem) DeleteObjects(ctx context.Context, typeName string, objects []Object) {
+func (provider *FileSystem) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
 	switch typeName {
 	case typeFile:
-		provider.deleteFiles(ctx, objects)
+		return provider.deleteFiles(ctx, objects)
 	case typeFolder:
-		provider.deleteFolders(ctx, objects)
+		return provider.deleteFolders(ctx, objects)
+	}
+	return nil
+}
+
+// ExistingObjects checks objects with a stat of their path instead of walking
+// the whole tree.
+func (provider *FileSystem) ExistingObjects(ctx context.Context, typeName string, objects []Object) ([]Object, error) {
+	existing := make([]Object, 0, len(objects))
+	for _, object := range objects {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := os.Lstat(path.Join(provider.Path, object.Id))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("stat: %w", err)
+		}
+		if (typeName == typeFile && info.Mode().IsRegular()) || (typeName == typeFolder && info.IsDir()) {
+			existing = append(existing, object)
+		}
 	}
+	return existing, nil
 }
 
-func (provider *FileSystem) StringObject(object string, typeName string) string {
+func (provider *FileSystem) StringObject(object Object, typeName string) string {
+	return object.Id
+}
+
+func newFsObject(nodePath string, node os.DirEntry) Object {
+	object := NewObject(nodePath)
+	object.Name = node.Name()
+	if info, err := node.Info(); err == nil {
+		object.SetCreatedAt(info.ModTime())
+		object.SetAttribute("mode", info.Mode().String())
+		if info.Mode().IsRegular() {
+			object.SetAttribute("size", strconv.FormatInt(info.Size(), 10))
+		}
+	}
 	return object
 }
 
 func (provider *FileSystem) readFiles(ctx context.Context) ([]Object, error) {
 	files := make([]Object, 0)
 
-	if err := os.Chdir(provider.Path); err != nil {
-		return nil, fmt.Errorf("chdir: %w", err)
-	}
-
 	folderStack := []string{"."}
 	for len(folderStack) > 0 {
 		if err := ctx.Err(); err != nil {
@@ -103,7 +153,7 @@ func (provider *FileSystem) readFiles(ctx context.Context) ([]Object, error) {
 
 		dirPath := folderStack[len(folderStack)-1]
 		folderStack = folderStack[:len(folderStack)-1]
-		dir, err := os.ReadDir(dirPath)
+		dir, err := os.ReadDir(path.Join(provider.Path, dirPath))
 		if err != nil {
 			return nil, fmt.Errorf("read dir: %w", err)
 		}
@@ -113,36 +163,36 @@ func (provider *FileSystem) readFiles(ctx context.Context) ([]Object, error) {
 				folderStack = append(folderStack, nodePath)
 			}
 			if node.Type().IsRegular() {
-				files = append(files, nodePath)
+				files = append(files, newFsObject(nodePath, node))
 			}
 		}
 	}
 	return files, nil
 }
 
-func (provider *FileSystem) deleteFiles(ctx context.Context, files []Object) {
-	for _, relativeFilePath := range files {
+func (provider *FileSystem) deleteFiles(ctx context.Context, files []Object) []DeleteResult {
+	results := make([]DeleteResult, 0, len(files))
+	for _, file := range files {
 		if ctx.Err() != nil {
-			return
+			return results
 		}
 
-		filePath := path.Join(provider.Path, relativeFilePath)
-		log.Printf("Deleting file %s ... ", filePath)
+		filePath := path.Join(provider.Path, file.Id)
+		ContextLogger(ctx).Printf("Deleting file %s ... ", filePath)
 		if err := os.Remove(filePath); err != nil {
-			log.Printf("cannot remove file %s\n", err.Error())
+			ContextLogger(ctx).Printf("cannot remove file %s\n", err.Error())
+			results = append(results, newDeleteResult(file, err))
 			continue
 		}
-		log.Println("OK")
+		ContextLogger(ctx).Println("OK")
+		results = append(results, DeleteSucceeded(file))
 	}
+	return results
 }
 
 func (provider *FileSystem) readFolders(ctx context.Context) ([]Object, error) {
 	folders := make([]Object, 0)
 
-	if err := os.Chdir(provider.Path); err != nil {
-		return nil, fmt.Errorf("chdir: %w", err)
-	}
-
 	folderStack := []string{"."}
 	for len(folderStack) > 0 {
 		if err := ctx.Err(); err != nil {
@@ -151,34 +201,51 @@ func (provider *FileSystem) readFolders(ctx context.Context) ([]Object, error) {
 
 		dirPath := folderStack[len(folderStack)-1]
 		folderStack = folderStack[:len(folderStack)-1]
-		dir, err := os.ReadDir(dirPath)
+		dir, err := os.ReadDir(path.Join(provider.Path, dirPath))
 		if err != nil {
-			log.Printf("cannot read directory: %s", err.Error())
+			ContextLogger(ctx).Printf("cannot read directory: %s", err.Error())
 			continue
 		}
 		for _, node := range dir {
 			nodePath := path.Join(dirPath, node.Name())
 			if node.IsDir() {
 				folderStack = append(folderStack, nodePath)
-				folders = append(folders, nodePath)
+				folders = append(folders, newFsObject(nodePath, node))
 			}
 		}
 	}
 	return folders, nil
 }
 
-func (provider *FileSystem) deleteFolders(ctx context.Context, folders []Object) {
-	for _, relativeFolderPath := range folders {
+func (provider *FileSystem) deleteFolders(ctx context.Context, folders []Object) []DeleteResult {
+	results := make([]DeleteResult, 0, len(folders))
+	for _, folder := range folders {
 		if ctx.Err() != nil {
-			return
+			return results
 		}
 
-		folderPath := path.Join(provider.Path, relativeFolderPath)
-		log.Printf("Deleting folder %s ... ", folderPath)
+		folderPath := path.Join(provider.Path, folder.Id)
+		ContextLogger(ctx).Printf("Deleting folder %s ... ", folderPath)
 		if err := os.Remove(folderPath); err != nil {
-			log.Printf("cannot remove folder %s\n", err.Error())
+			ContextLogger(ctx).Printf("cannot remove folder %s\n", err.Error())
+			results = append(results, newDeleteResult(folder, err))
 			continue
 		}
-		log.Println("OK")
+		ContextLogger(ctx).Println("OK")
+		results = append(results, DeleteSucceeded(folder))
+	}
+	return results
+}
+
+func newDeleteResult(object Object, err error) DeleteResult {
+	kind := DeleteErrorRetryable
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		kind = DeleteErrorNotFound
+	case errors.Is(err, fs.ErrPermission):
+		kind = DeleteErrorForbidden
+	case errors.Is(err, syscall.ENOTEMPTY), errors.Is(err, syscall.EEXIST):
+		kind = DeleteErrorDependency
 	}
+	return DeleteFailed(object, kind, err)
 }
@@ -2,17 +2,18 @@ package outscale_oapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/outscale/frieza/internal/common"
+	"github.com/outscale/osc-sdk-go/v3/pkg/middleware"
 	"github.com/outscale/osc-sdk-go/v3/pkg/options"
 	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
 	"github.com/outscale/osc-sdk-go/v3/pkg/profile"
-	"github.com/teris-io/cli"
 )
 
 const (
@@ -50,20 +51,26 @@ const (
 	typeDhcpOption        = "dhcp_option"
 )
 
+// defaultRateLimit is the rate limit of the SDK.
+const defaultRateLimit = 5
+
 type OutscaleOAPI struct {
 	client *osc.Client
-	cache  apiCache
+	cache  *apiCache
+	region string
 }
 
+// apiCache is shared by types read concurrently.
 type apiCache struct {
+	accountIdMutex   sync.Mutex
 	accountId        *string
-	internetServices map[Object]*osc.InternetService
-	publicIps        map[Object]*osc.PublicIp
-	vms              map[Object]*osc.Vm
-	nics             map[Object]*osc.Nic
-	routeTables      map[Object]*osc.RouteTable
-	securityGroups   map[Object]*osc.SecurityGroup
-	flexibleGpus     map[Object]*osc.FlexibleGpu
+	internetServices *cacheMap[osc.InternetService]
+	publicIps        *cacheMap[osc.PublicIp]
+	vms              *cacheMap[osc.Vm]
+	nics             *cacheMap[osc.Nic]
+	routeTables      *cacheMap[osc.RouteTable]
+	securityGroups   *cacheMap[osc.SecurityGroup]
+	flexibleGpus     *cacheMap[osc.FlexibleGpu]
 }
 
 func New(config ProviderConfig, debug bool) (*OutscaleOAPI, error) {
@@ -86,7 +93,15 @@ func New(config ProviderConfig, debug bool) (*OutscaleOAPI, error) {
 		profile.Region = region
 	}
 
-	client, err := osc.NewClient(profile, options.WithUseragent("frieza/"+FullVersion()))
+	rate, err := ParseRateLimit(config, defaultRateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := osc.NewClient(profile,
+		options.WithUseragent("frieza/"+FullVersion()),
+		middleware.WithMiddleware(middleware.MiddlewareSlotRateLimit, NewRateLimiter(rate)),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +109,7 @@ func New(config ProviderConfig, debug bool) (*OutscaleOAPI, error) {
 	return &OutscaleOAPI{
 		client: client,
 		cache:  newAPICache(),
+		region: profile.Region,
 	}, nil
 }
 
@@ -133,11 +149,75 @@ func Types() []ObjectType {
 	return object_types
 }
 
-func Cli() (string, cli.Command) {
-	return Name, cli.NewCommand(Name, "create new Outscale API profile").
-		WithOption(cli.NewOption("region", "Outscale region (e.g. eu-west-2)")).
-		WithOption(cli.NewOption("ak", "access key")).
-		WithOption(cli.NewOption("sk", "secret key"))
+// TaggedTypes lists types whose read calls return tags.
+func TaggedTypes() []ObjectType {
+	return []ObjectType{
+		typeVm,
+		typeLoadBalancer,
+		typeSecurityGroup,
+		typeInternetService,
+		typeRouteTable,
+		typeNatService,
+		typeNic,
+		typeVpnConnection,
+		typeVirtualGateway,
+		typeClientGateway,
+		typePublicIp,
+		typeNetAccessPoint,
+		typeNetPeering,
+		typeSubnet,
+		typeNet,
+		typeVolume,
+		typeImage,
+		typeSnapshot,
+		typeKeypair,
+		typeFlexibleGpu,
+		typeDhcpOption,
+	}
+}
+
+// Dependencies lists, for each type, the types which must be deleted first.
+func Dependencies() map[ObjectType][]ObjectType {
+	dependencies := map[ObjectType][]ObjectType{
+		typeSecurityGroup:     {typeVm, typeLoadBalancer, typeNic},
+		typeInternetService:   {typeVm, typeLoadBalancer, typeNatService},
+		typeNic:               {typeVm, typeLoadBalancer, typeNatService},
+		typeVirtualGateway:    {typeVpnConnection},
+		typeClientGateway:     {typeVpnConnection},
+		typePublicIp:          {typeNatService},
+		typeSubnet:            {typeVm, typeLoadBalancer, typeNatService, typeNic},
+		typeNet:               {typeSubnet, typeSecurityGroup, typeInternetService, typeRouteTable, typeNetAccessPoint, typeNetPeering},
+		typeVolume:            {typeVm},
+		typeSnapshot:          {typeImage},
+		typeUser:              {typeUserAccessKey, typePolicyLink},
+		typeUserGroup:         {typePolicyLink},
+		typePolicy:            {typePolicyLink, typePolicyVersion},
+		typeFlexibleGpu:       {typeVm},
+		typeServerCertificate: {typeLoadBalancer},
+		typeDhcpOption:        {typeNet},
+	}
+	// Access keys are used to authenticate frieza itself: delete them last.
+	for _, typeName := range Types() {
+		if typeName != typeAccessKey {
+			dependencies[typeAccessKey] = append(dependencies[typeAccessKey], typeName)
+		}
+	}
+	return dependencies
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:        Name,
+		Description: "Outscale API",
+		New:         ProviderConstructor(New),
+		Types:       Types,
+		Config: []ProviderConfigOption{
+			{Name: "region", Description: "Outscale region (e.g. eu-west-2)"},
+			{Name: "ak", Description: "access key"},
+			{Name: "sk", Description: "secret key"},
+			RateLimitOption(defaultRateLimit),
+		},
+	})
 }
 
 func (provider *OutscaleOAPI) Name() string {
@@ -148,6 +228,19 @@ func (provider *OutscaleOAPI) Types() []ObjectType {
 	return Types()
 }
 
+func (provider *OutscaleOAPI) Dependencies() map[ObjectType][]ObjectType {
+	return Dependencies()
+}
+
+func (provider *OutscaleOAPI) TaggedTypes() []ObjectType {
+	return TaggedTypes()
+}
+
+// MaxConcurrency keeps simultaneous reads under the API rate limits.
+func (provider *OutscaleOAPI) MaxConcurrency() int {
+	return 4
+}
+
 func (provider *OutscaleOAPI) AuthTest(ctx context.Context) error {
 	_, err := provider.readAccountId(ctx)
 	return err
@@ -156,17 +249,17 @@ func (provider *OutscaleOAPI) AuthTest(ctx context.Context) error {
 func (provider *OutscaleOAPI) ReadObjects(ctx context.Context, typeName string) ([]Object, error) {
 	switch typeName {
 	case typeVm:
-		return provider.readVms(ctx)
+		return provider.readVms(ctx, nil)
 	case typeLoadBalancer:
-		return provider.readLoadBalancers(ctx)
+		return provider.readLoadBalancers(ctx, nil)
 	case typeNatService:
-		return provider.readNatServices(ctx)
+		return provider.readNatServices(ctx, nil)
 	case typeSecurityGroup:
-		return provider.readSecurityGroups(ctx)
+		return provider.readSecurityGroups(ctx, nil)
 	case typePublicIp:
 		return provider.readPublicIps(ctx)
 	case typeVolume:
-		return provider.readVolumes(ctx)
+		return provider.readVolumes(ctx, nil)
 	case typeKeypair:
 		return provider.readKeypairs(ctx)
 	case typeRouteTable:
@@ -174,13 +267,13 @@ func (provider *OutscaleOAPI) ReadObjects(ctx context.Context, typeName string)
 	case typeInternetService:
 		return provider.readInternetServices(ctx)
 	case typeSubnet:
-		return provider.readSubnets(ctx)
+		return provider.readSubnets(ctx, nil)
 	case typeNet:
-		return provider.readNets(ctx)
+		return provider.readNets(ctx, nil)
 	case typeImage:
-		return provider.readImages(ctx)
+		return provider.readImages(ctx, nil)
 	case typeSnapshot:
-		return provider.readSnapshots(ctx)
+		return provider.readSnapshots(ctx, nil)
 	case typeVpnConnection:
 		return provider.readVpnConnections(ctx)
 	case typeVirtualGateway:
@@ -188,7 +281,7 @@ func (provider *OutscaleOAPI) ReadObjects(ctx context.Context, typeName string)
 	case typeClientGateway:
 		return provider.readClientGateways(ctx)
 	case typeNic:
-		return provider.readNics(ctx)
+		return provider.readNics(ctx, nil)
 	case typeAccessKey:
 		return provider.readAccessKeys(ctx)
 	case typeNetAccessPoint:
@@ -219,91 +312,155 @@ func (provider *OutscaleOAPI) ReadObjects(ctx context.Context, typeName string)
 	return []Object{}, nil
 }
 
-func (provider *OutscaleOAPI) DeleteObjects(ctx context.Context, typeName string, objects []Object) {
+// ExistingObjects reads objects by ID, for the types whose API call can filter
+// on them.
+func (provider *OutscaleOAPI) ExistingObjects(ctx context.Context, typeName string, objects []Object) ([]Object, error) {
+	ids := ObjectIds(objects)
+	switch typeName {
+	case typeVm:
+		return provider.readVms(ctx, &ids)
+	case typeLoadBalancer:
+		return provider.readLoadBalancers(ctx, &ids)
+	case typeNatService:
+		return provider.readNatServices(ctx, &ids)
+	case typeSecurityGroup:
+		return provider.readSecurityGroups(ctx, &ids)
+	case typeVolume:
+		return provider.readVolumes(ctx, &ids)
+	case typeSubnet:
+		return provider.readSubnets(ctx, &ids)
+	case typeNet:
+		return provider.readNets(ctx, &ids)
+	case typeImage:
+		return provider.readImages(ctx, &ids)
+	case typeSnapshot:
+		return provider.readSnapshots(ctx, &ids)
+	case typeNic:
+		return provider.readNics(ctx, &ids)
+	}
+	return nil, errors.ErrUnsupported
+}
+
+// checkLinked returns a *ProtectedError when the object of typeName with id,
+// linked to an object being deleted, is protected. It is read again as rules
+// can match its current name or tags.
+func (provider *OutscaleOAPI) checkLinked(ctx context.Context, typeName ObjectType, id string) error {
+	if !HasProtectRules(ctx) || len(id) == 0 {
+		return nil
+	}
+	objects, err := provider.ExistingObjects(ctx, typeName, []Object{NewObject(id)})
+	if err != nil {
+		return err
+	}
+	return CheckProtected(ctx, typeName, objects)
+}
+
+// checkSecurityGroupVms returns a *ProtectedError when a protected VM uses the
+// security group.
+func (provider *OutscaleOAPI) checkSecurityGroupVms(ctx context.Context, securityGroupId string) error {
+	if !HasProtectRules(ctx) {
+		return nil
+	}
+	read, err := provider.client.ReadVms(ctx, osc.ReadVmsRequest{
+		Filters: &osc.FiltersVm{VmSecurityGroupIds: &[]string{securityGroupId}},
+	})
+	if err != nil {
+		return fmt.Errorf("read vms of security group %s: %w", securityGroupId, getErrorInfo(err))
+	}
+	vms := make([]Object, 0, len(*read.Vms))
+	for _, vm := range *read.Vms {
+		vms = append(vms, provider.newObject(vm.VmId, vm.Tags))
+	}
+	return CheckProtected(ctx, typeVm, vms)
+}
+
+func (provider *OutscaleOAPI) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
 	switch typeName {
 	case typeVm:
-		provider.deleteVms(ctx, objects)
+		return provider.deleteVms(ctx, objects)
 	case typeLoadBalancer:
-		provider.deleteLoadBalancers(ctx, objects)
+		return provider.deleteLoadBalancers(ctx, objects)
 	case typeNatService:
-		provider.deleteNatServices(ctx, objects)
+		return provider.deleteNatServices(ctx, objects)
 	case typeSecurityGroup:
-		provider.deleteSecurityGroups(ctx, objects)
+		return provider.deleteSecurityGroups(ctx, objects)
 	case typePublicIp:
-		provider.deletePublicIps(ctx, objects)
+		return provider.deletePublicIps(ctx, objects)
 	case typeVolume:
-		provider.deleteVolumes(ctx, objects)
+		return provider.deleteVolumes(ctx, objects)
 	case typeKeypair:
-		provider.deleteKeypairs(ctx, objects)
+		return provider.deleteKeypairs(ctx, objects)
 	case typeRouteTable:
-		provider.deleteRouteTables(ctx, objects)
+		return provider.deleteRouteTables(ctx, objects)
 	case typeInternetService:
-		provider.deleteInternetServices(ctx, objects)
+		return provider.deleteInternetServices(ctx, objects)
 	case typeSubnet:
-		provider.deleteSubnets(ctx, objects)
+		return provider.deleteSubnets(ctx, objects)
 	case typeNet:
-		provider.deleteNets(ctx, objects)
+		return provider.deleteNets(ctx, objects)
 	case typeImage:
-		provider.deleteImages(ctx, objects)
+		return provider.deleteImages(ctx, objects)
 	case typeSnapshot:
-		provider.deleteSnapshots(ctx, objects)
+		return provider.deleteSnapshots(ctx, objects)
 	case typeVpnConnection:
-		provider.deleteVpnConnections(ctx, objects)
+		return provider.deleteVpnConnections(ctx, objects)
 	case typeVirtualGateway:
-		provider.deleteVirtualGateways(ctx, objects)
+		return provider.deleteVirtualGateways(ctx, objects)
 	case typeClientGateway:
-		provider.deleteClientGateways(ctx, objects)
+		return provider.deleteClientGateways(ctx, objects)
 	case typeNic:
-		provider.deleteNics(ctx, objects)
+		return provider.deleteNics(ctx, objects)
 	case typeAccessKey:
-		provider.deleteAccessKeys(ctx, objects)
+		return provider.deleteAccessKeys(ctx, objects)
 	case typeNetAccessPoint:
-		provider.deleteNetAccessPoints(ctx, objects)
+		return provider.deleteNetAccessPoints(ctx, objects)
 	case typeNetPeering:
-		provider.deleteNetPeerings(ctx, objects)
+		return provider.deleteNetPeerings(ctx, objects)
 	case typeUser:
-		provider.deleteUsers(ctx, objects)
+		return provider.deleteUsers(ctx, objects)
 	case typeUserGroup:
-		provider.deleteUserGroups(ctx, objects)
+		return provider.deleteUserGroups(ctx, objects)
 	case typeUserAccessKey:
-		provider.deleteUserAccessKeys(ctx, objects)
+		return provider.deleteUserAccessKeys(ctx, objects)
 	case typePolicy:
-		provider.deletePolicies(ctx, objects)
+		return provider.deletePolicies(ctx, objects)
 	case typePolicyLink:
-		provider.deletePolicyLinks(ctx, objects)
+		return provider.deletePolicyLinks(ctx, objects)
 	case typePolicyVersion:
-		provider.deletePolicyVersions(ctx, objects)
+		return provider.deletePolicyVersions(ctx, objects)
 	case typeFlexibleGpu:
-		provider.deleteFlexibleGpus(ctx, objects)
+		return provider.deleteFlexibleGpus(ctx, objects)
 	case typeCa:
-		provider.deleteCas(ctx, objects)
+		return provider.deleteCas(ctx, objects)
 	case typeServerCertificate:
-		provider.deleteServerCertificates(ctx, objects)
+		return provider.deleteServerCertificates(ctx, objects)
 	case typeDhcpOption:
-		provider.deleteDhcpOptions(ctx, objects)
+		return provider.deleteDhcpOptions(ctx, objects)
 	}
+	return nil
 }
 
-func (provider *OutscaleOAPI) StringObject(object string, typeName string) string {
-	return object
+func (provider *OutscaleOAPI) StringObject(object Object, typeName string) string {
+	return object.String()
 }
 
-func newAPICache() apiCache {
-	return apiCache{
-		internetServices: make(map[string]*osc.InternetService),
-		publicIps:        make(map[string]*osc.PublicIp),
-		vms:              make(map[string]*osc.Vm),
-		nics:             make(map[string]*osc.Nic),
-		routeTables:      make(map[string]*osc.RouteTable),
-		securityGroups:   make(map[string]*osc.SecurityGroup),
-		flexibleGpus:     make(map[string]*osc.FlexibleGpu),
+func newAPICache() *apiCache {
+	return &apiCache{
+		internetServices: newCacheMap[osc.InternetService](),
+		publicIps:        newCacheMap[osc.PublicIp](),
+		vms:              newCacheMap[osc.Vm](),
+		nics:             newCacheMap[osc.Nic](),
+		routeTables:      newCacheMap[osc.RouteTable](),
+		securityGroups:   newCacheMap[osc.SecurityGroup](),
+		flexibleGpus:     newCacheMap[osc.FlexibleGpu](),
 	}
 }
 
-func (provider *OutscaleOAPI) readVms(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readVms(ctx context.Context, ids *[]string) ([]Object, error) {
 	vms := make([]Object, 0)
 	read, err := provider.client.ReadVms(ctx, osc.ReadVmsRequest{
 		Filters: &osc.FiltersVm{
+			VmIds: ids,
 			VmStateNames: &[]osc.VmState{
 				"pending", "running", "stopping", "stopped", "shutting-down", "quarantine", // skipping terminated
 			},
@@ -313,16 +470,24 @@ func (provider *OutscaleOAPI) readVms(ctx context.Context) ([]Object, error) {
 		return nil, fmt.Errorf("read vms: %w", getErrorInfo(err))
 	}
 	for i, vm := range *read.Vms {
-		vms = append(vms, vm.VmId)
-		provider.cache.vms[vm.VmId] = &(*read.Vms)[i]
+		object := provider.newObject(vm.VmId, vm.Tags)
+		object.SetCreatedAt(vm.CreationDate.Time)
+		object.SetAttribute("state", string(vm.State))
+		object.SetAttribute("vm_type", vm.VmType)
+		if vm.NetId != nil {
+			object.SetAttribute("net_id", *vm.NetId)
+		}
+		vms = append(vms, object)
+		provider.cache.vms.set(vm.VmId, &(*read.Vms)[i])
 	}
 	return vms, nil
 }
 
 func (provider *OutscaleOAPI) forceShutdownVms(ctx context.Context, vms []Object) {
-	var vmsToForce []Object
-	for _, vmId := range vms {
-		vm := provider.cache.vms[vmId]
+	var vmsToForce []string
+	for _, vmObject := range vms {
+		vmId := vmObject.Id
+		vm := provider.cache.vms.get(vmId)
 		if vm == nil {
 			continue
 		}
@@ -335,7 +500,7 @@ func (provider *OutscaleOAPI) forceShutdownVms(ctx context.Context, vms []Object
 		return
 	}
 
-	log.Printf("Shutting down virtual machines: %v...\n", vmsToForce)
+	ContextLogger(ctx).Printf("Shutting down virtual machines: %v...\n", vmsToForce)
 	forceStop := true
 	stopOpts := osc.StopVmsRequest{
 		VmIds:     vmsToForce,
@@ -343,33 +508,39 @@ func (provider *OutscaleOAPI) forceShutdownVms(ctx context.Context, vms []Object
 	}
 	_, err := provider.client.StopVms(ctx, stopOpts)
 	if err != nil {
-		log.Printf("Error while shutting down vms: %v\n", getErrorInfo(err))
+		ContextLogger(ctx).Printf("Error while shutting down vms: %v\n", getErrorInfo(err))
 		return
 	}
-	log.Println("OK")
+	ContextLogger(ctx).Println("OK")
 }
 
-func (provider *OutscaleOAPI) deleteVms(ctx context.Context, vms []Object) {
+func (provider *OutscaleOAPI) deleteVms(ctx context.Context, vms []Object) []DeleteResult {
 	if len(vms) == 0 {
-		return
+		return nil
 	}
 	provider.forceShutdownVms(ctx, vms)
-	log.Printf("Deleting virtual machines: %s ... ", vms)
-	deletionOpts := osc.DeleteVmsRequest{VmIds: vms}
+	ContextLogger(ctx).Printf("Deleting virtual machines: %s ... ", vms)
+	deletionOpts := osc.DeleteVmsRequest{VmIds: ObjectIds(vms)}
 	_, err := provider.client.DeleteVms(ctx, deletionOpts)
 	if err != nil {
-		log.Printf("Error while deleting vms: %v\n", getErrorInfo(err))
+		ContextLogger(ctx).Printf("Error while deleting vms: %v\n", getErrorInfo(err))
 	} else {
-		log.Println("OK")
+		ContextLogger(ctx).Println("OK")
 	}
+	results := make([]DeleteResult, 0, len(vms))
+	for _, vm := range vms {
+		results = append(results, newDeleteResult(vm, err))
+	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readLoadBalancers(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readLoadBalancers(ctx context.Context, ids *[]string) ([]Object, error) {
 	loadBalancers := make([]Object, 0)
 	read, err := provider.client.ReadLoadBalancers(
 		ctx,
 		osc.ReadLoadBalancersRequest{
 			Filters: &osc.FiltersLoadBalancer{
+				LoadBalancerNames: ids,
 				States: &[]osc.LoadBalancerState{ // skipping deleted, deleting
 					osc.LoadBalancerStateActive, osc.LoadBalancerStateProvisioning, osc.LoadBalancerStateReconfiguring, osc.LoadBalancerStateReloading, osc.LoadBalancerStateStarting,
 				},
@@ -380,33 +551,44 @@ func (provider *OutscaleOAPI) readLoadBalancers(ctx context.Context) ([]Object,
 		return nil, fmt.Errorf("read load balancers: %w", getErrorInfo(err))
 	}
 	for _, loadBalancer := range *read.LoadBalancers {
-		loadBalancers = append(loadBalancers, loadBalancer.LoadBalancerName)
+		object := provider.newObject(loadBalancer.LoadBalancerName, loadBalancer.Tags)
+		object.Name = loadBalancer.LoadBalancerName
+		object.SetAttribute("state", string(loadBalancer.State))
+		object.SetAttribute("dns_name", loadBalancer.DnsName)
+		if loadBalancer.NetId != nil {
+			object.SetAttribute("net_id", *loadBalancer.NetId)
+		}
+		loadBalancers = append(loadBalancers, object)
 	}
 	return loadBalancers, nil
 }
 
-func (provider *OutscaleOAPI) deleteLoadBalancers(ctx context.Context, loadBalancers []Object) {
+func (provider *OutscaleOAPI) deleteLoadBalancers(ctx context.Context, loadBalancers []Object) []DeleteResult {
 	if len(loadBalancers) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(loadBalancers))
 	for _, loadBalancer := range loadBalancers {
-		log.Printf("Deleting load balancer %s... ", loadBalancer)
-		deletionOpts := osc.DeleteLoadBalancerRequest{LoadBalancerName: loadBalancer}
+		ContextLogger(ctx).Printf("Deleting load balancer %s... ", loadBalancer)
+		deletionOpts := osc.DeleteLoadBalancerRequest{LoadBalancerName: loadBalancer.Id}
 		_, err := provider.client.DeleteLoadBalancer(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting load balancer: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting load balancer: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(loadBalancer, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readNatServices(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readNatServices(ctx context.Context, ids *[]string) ([]Object, error) {
 	natServices := make([]Object, 0)
 	read, err := provider.client.ReadNatServices(
 		ctx,
 		osc.ReadNatServicesRequest{
 			Filters: &osc.FiltersNatService{
+				NatServiceIds: ids,
 				States: &[]osc.NatServiceState{
 					"pending", "available", // skipping deleting, deleted
 				},
@@ -417,32 +599,41 @@ func (provider *OutscaleOAPI) readNatServices(ctx context.Context) ([]Object, er
 		return nil, fmt.Errorf("read nat: %w", getErrorInfo(err))
 	}
 	for _, natService := range *read.NatServices {
-		natServices = append(natServices, natService.NatServiceId)
+		object := provider.newObject(natService.NatServiceId, natService.Tags)
+		object.SetAttribute("state", string(natService.State))
+		object.SetAttribute("net_id", natService.NetId)
+		object.SetAttribute("subnet_id", natService.SubnetId)
+		natServices = append(natServices, object)
 	}
 	return natServices, nil
 }
 
-func (provider *OutscaleOAPI) deleteNatServices(ctx context.Context, natServices []Object) {
+func (provider *OutscaleOAPI) deleteNatServices(ctx context.Context, natServices []Object) []DeleteResult {
 	if len(natServices) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(natServices))
 	for _, natService := range natServices {
-		log.Printf("Deleting nat service %s... ", natService)
-		deletionOpts := osc.DeleteNatServiceRequest{NatServiceId: natService}
+		ContextLogger(ctx).Printf("Deleting nat service %s... ", natService)
+		deletionOpts := osc.DeleteNatServiceRequest{NatServiceId: natService.Id}
 		_, err := provider.client.DeleteNatService(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting nat service: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting nat service: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(natService, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readSecurityGroups(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readSecurityGroups(ctx context.Context, ids *[]string) ([]Object, error) {
 	securityGroups := make([]Object, 0)
 	read, err := provider.client.ReadSecurityGroups(
 		ctx,
-		osc.ReadSecurityGroupsRequest{},
+		osc.ReadSecurityGroupsRequest{
+			Filters: &osc.FiltersSecurityGroup{SecurityGroupIds: ids},
+		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("read security groups: %w", getErrorInfo(err))
@@ -452,14 +643,19 @@ func (provider *OutscaleOAPI) readSecurityGroups(ctx context.Context) ([]Object,
 			continue
 		}
 		copySg := sg
-		securityGroups = append(securityGroups, sg.SecurityGroupId)
-		provider.cache.securityGroups[sg.SecurityGroupId] = &copySg
+		object := provider.newObject(sg.SecurityGroupId, sg.Tags)
+		object.Name = sg.SecurityGroupName
+		if sg.NetId != nil {
+			object.SetAttribute("net_id", *sg.NetId)
+		}
+		securityGroups = append(securityGroups, object)
+		provider.cache.securityGroups.set(sg.SecurityGroupId, &copySg)
 	}
 	return securityGroups, nil
 }
 
 func (provider *OutscaleOAPI) deleteSecurityGroupRules(ctx context.Context, securityGroupId string) error {
-	securityGroup := provider.cache.securityGroups[securityGroupId]
+	securityGroup := provider.cache.securityGroups.get(securityGroupId)
 	if securityGroup == nil ||
 		(securityGroup.InboundRules == nil && securityGroup.OutboundRules == nil) {
 		return nil
@@ -482,7 +678,7 @@ func (provider *OutscaleOAPI) deleteSecurityGroupRules(ctx context.Context, secu
 			rule.SecurityGroupsMembers = targetSecurityGroupMember
 			targetRules = append(targetRules, rule)
 		}
-		log.Printf("Deleting inbound security group rule from %s... ", securityGroupId)
+		ContextLogger(ctx).Printf("Deleting inbound security group rule from %s... ", securityGroupId)
 		delete := osc.DeleteSecurityGroupRuleRequest{
 			Flow:            "Inbound",
 			Rules:           targetRules,
@@ -491,13 +687,13 @@ func (provider *OutscaleOAPI) deleteSecurityGroupRules(ctx context.Context, secu
 
 		_, err := provider.client.DeleteSecurityGroupRule(ctx, delete)
 		if err != nil {
-			log.Printf(
+			ContextLogger(ctx).Printf(
 				"Error while deleting inbound rules of security group route %s: ",
 				securityGroupId,
 			)
 			return err
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
 	}
 
@@ -518,7 +714,7 @@ func (provider *OutscaleOAPI) deleteSecurityGroupRules(ctx context.Context, secu
 			rule.SecurityGroupsMembers = targetSecurityGroupMember
 			targetRules = append(targetRules, rule)
 		}
-		log.Printf("Deleting outbound security group rule from %s... ", securityGroupId)
+		ContextLogger(ctx).Printf("Deleting outbound security group rule from %s... ", securityGroupId)
 		delete := osc.DeleteSecurityGroupRuleRequest{
 			Flow:            "Outbound",
 			Rules:           targetRules,
@@ -527,35 +723,45 @@ func (provider *OutscaleOAPI) deleteSecurityGroupRules(ctx context.Context, secu
 
 		_, err := provider.client.DeleteSecurityGroupRule(ctx, delete)
 		if err != nil {
-			log.Printf(
+			ContextLogger(ctx).Printf(
 				"Error while deleting outbound rules of security group route %s: ",
 				securityGroupId,
 			)
 			return err
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
 	}
 	return nil
 }
 
-func (provider *OutscaleOAPI) deleteSecurityGroups(ctx context.Context, securityGroups []Object) {
+func (provider *OutscaleOAPI) deleteSecurityGroups(ctx context.Context, securityGroups []Object) []DeleteResult {
 	if len(securityGroups) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(securityGroups))
 	for _, sg := range securityGroups {
-		if provider.deleteSecurityGroupRules(ctx, sg) != nil {
+		// Rules are removed before deleting the group, which would cut off
+		// the protected VMs using it.
+		if err := provider.checkSecurityGroupVms(ctx, sg.Id); err != nil {
+			results = append(results, newDeleteResult(sg, err))
 			continue
 		}
-		log.Printf("Deleting security group %s... ", sg)
-		deletionOpts := osc.DeleteSecurityGroupRequest{SecurityGroupId: &sg}
+		if err := provider.deleteSecurityGroupRules(ctx, sg.Id); err != nil {
+			results = append(results, newDeleteResult(sg, err))
+			continue
+		}
+		ContextLogger(ctx).Printf("Deleting security group %s... ", sg)
+		deletionOpts := osc.DeleteSecurityGroupRequest{SecurityGroupId: &sg.Id}
 		_, err := provider.client.DeleteSecurityGroup(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting security groups: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting security groups: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(sg, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readPublicIps(ctx context.Context) ([]Object, error) {
@@ -568,14 +774,22 @@ func (provider *OutscaleOAPI) readPublicIps(ctx context.Context) ([]Object, erro
 		return nil, fmt.Errorf("read public ips: %w", getErrorInfo(err))
 	}
 	for i, pip := range *read.PublicIps {
-		publicIps = append(publicIps, pip.PublicIp)
-		provider.cache.publicIps[pip.PublicIp] = &(*read.PublicIps)[i]
+		object := provider.newObject(pip.PublicIp, pip.Tags)
+		object.SetAttribute("public_ip_id", pip.PublicIpId)
+		if pip.VmId != nil {
+			object.SetAttribute("vm_id", *pip.VmId)
+		}
+		if pip.NicId != nil {
+			object.SetAttribute("nic_id", *pip.NicId)
+		}
+		publicIps = append(publicIps, object)
+		provider.cache.publicIps.set(pip.PublicIp, &(*read.PublicIps)[i])
 	}
 	return publicIps, nil
 }
 
 func (provider *OutscaleOAPI) unlinkPublicIp(ctx context.Context, publicIP *string) error {
-	cache := provider.cache.publicIps[*publicIP]
+	cache := provider.cache.publicIps.get(*publicIP)
 	if cache == nil {
 		return nil
 	}
@@ -584,40 +798,55 @@ func (provider *OutscaleOAPI) unlinkPublicIp(ctx context.Context, publicIP *stri
 		cache.VmId == nil {
 		return nil
 	}
-	log.Printf("Unlinking public ip %s... ", *publicIP)
+	if cache.VmId != nil {
+		if err := provider.checkLinked(ctx, typeVm, *cache.VmId); err != nil {
+			return err
+		}
+	}
+	if cache.NicId != nil {
+		if err := provider.checkLinked(ctx, typeNic, *cache.NicId); err != nil {
+			return err
+		}
+	}
+	ContextLogger(ctx).Printf("Unlinking public ip %s... ", *publicIP)
 	unlinkOpts := osc.UnlinkPublicIpRequest{PublicIp: publicIP}
 	_, err := provider.client.UnlinkPublicIp(ctx, unlinkOpts)
 	if err != nil {
-		log.Printf("Error while unlinking public ip: %v\n", getErrorInfo(err))
+		ContextLogger(ctx).Printf("Error while unlinking public ip: %v\n", getErrorInfo(err))
 		return err
 	}
-	log.Println("OK")
+	ContextLogger(ctx).Println("OK")
 	return nil
 }
 
-func (provider *OutscaleOAPI) deletePublicIps(ctx context.Context, publicIps []Object) {
+func (provider *OutscaleOAPI) deletePublicIps(ctx context.Context, publicIps []Object) []DeleteResult {
 	if len(publicIps) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(publicIps))
 	for _, publicIP := range publicIps {
-		if provider.unlinkPublicIp(ctx, &publicIP) != nil {
+		if err := provider.unlinkPublicIp(ctx, &publicIP.Id); err != nil {
+			results = append(results, newDeleteResult(publicIP, err))
 			continue
 		}
-		log.Printf("Deleting public ip %s... ", publicIP)
-		deletionOpts := osc.DeletePublicIpRequest{PublicIp: &publicIP}
+		ContextLogger(ctx).Printf("Deleting public ip %s... ", publicIP)
+		deletionOpts := osc.DeletePublicIpRequest{PublicIp: &publicIP.Id}
 		_, err := provider.client.DeletePublicIp(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting public ip: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting public ip: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(publicIP, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readVolumes(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readVolumes(ctx context.Context, ids *[]string) ([]Object, error) {
 	volumes := make([]Object, 0)
 	read, err := provider.client.ReadVolumes(ctx, osc.ReadVolumesRequest{
 		Filters: &osc.FiltersVolume{
+			VolumeIds: ids,
 			VolumeStates: &[]osc.VolumeState{
 				"creating", "available", "in-use", "error",
 			},
@@ -632,25 +861,32 @@ func (provider *OutscaleOAPI) readVolumes(ctx context.Context) ([]Object, error)
 		if volume.State == "deleting" {
 			continue
 		}
-		volumes = append(volumes, volume.VolumeId)
+		object := provider.newObject(volume.VolumeId, volume.Tags)
+		object.SetCreatedAt(volume.CreationDate.Time)
+		object.SetAttribute("state", string(volume.State))
+		object.SetAttribute("subregion", volume.SubregionName)
+		volumes = append(volumes, object)
 	}
 	return volumes, nil
 }
 
-func (provider *OutscaleOAPI) deleteVolumes(ctx context.Context, volumes []Object) {
+func (provider *OutscaleOAPI) deleteVolumes(ctx context.Context, volumes []Object) []DeleteResult {
 	if len(volumes) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(volumes))
 	for _, volume := range volumes {
-		log.Printf("Deleting volume %s... ", volume)
-		deletionOpts := osc.DeleteVolumeRequest{VolumeId: volume}
+		ContextLogger(ctx).Printf("Deleting volume %s... ", volume)
+		deletionOpts := osc.DeleteVolumeRequest{VolumeId: volume.Id}
 		_, err := provider.client.DeleteVolume(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting volume: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting volume: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(volume, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readKeypairs(ctx context.Context) ([]Object, error) {
@@ -660,25 +896,37 @@ func (provider *OutscaleOAPI) readKeypairs(ctx context.Context) ([]Object, error
 		return nil, fmt.Errorf("read key pairs: %w", getErrorInfo(err))
 	}
 	for _, keypair := range *read.Keypairs {
-		keypairs = append(keypairs, *keypair.KeypairName)
+		var tags []osc.ResourceTag
+		if keypair.Tags != nil {
+			tags = *keypair.Tags
+		}
+		object := provider.newObject(*keypair.KeypairName, tags)
+		object.Name = *keypair.KeypairName
+		if keypair.KeypairId != nil {
+			object.SetAttribute("keypair_id", *keypair.KeypairId)
+		}
+		keypairs = append(keypairs, object)
 	}
 	return keypairs, nil
 }
 
-func (provider *OutscaleOAPI) deleteKeypairs(ctx context.Context, keypairs []Object) {
+func (provider *OutscaleOAPI) deleteKeypairs(ctx context.Context, keypairs []Object) []DeleteResult {
 	if len(keypairs) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(keypairs))
 	for _, keypair := range keypairs {
-		log.Printf("Deleting keypair %s... ", keypair)
-		deletionOpts := osc.DeleteKeypairRequest{KeypairName: &keypair}
+		ContextLogger(ctx).Printf("Deleting keypair %s... ", keypair)
+		deletionOpts := osc.DeleteKeypairRequest{KeypairName: &keypair.Id}
 		_, err := provider.client.DeleteKeypair(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting keypair: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting keypair: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(keypair, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readRouteTables(ctx context.Context) ([]Object, error) {
@@ -694,14 +942,16 @@ func (provider *OutscaleOAPI) readRouteTables(ctx context.Context) ([]Object, er
 		if provider.isMainRouteTable(&routeTable) {
 			continue
 		}
-		routeTables = append(routeTables, routeTable.RouteTableId)
-		provider.cache.routeTables[routeTable.RouteTableId] = &(*read.RouteTables)[i]
+		object := provider.newObject(routeTable.RouteTableId, routeTable.Tags)
+		object.SetAttribute("net_id", routeTable.NetId)
+		routeTables = append(routeTables, object)
+		provider.cache.routeTables.set(routeTable.RouteTableId, &(*read.RouteTables)[i])
 	}
 	return routeTables, nil
 }
 
 func (provider *OutscaleOAPI) unlinkRouteTable(ctx context.Context, routeTableId string) error {
-	routeTable := provider.cache.routeTables[routeTableId]
+	routeTable := provider.cache.routeTables.get(routeTableId)
 	if routeTable == nil || routeTable.LinkRouteTables == nil {
 		return nil
 	}
@@ -710,13 +960,13 @@ func (provider *OutscaleOAPI) unlinkRouteTable(ctx context.Context, routeTableId
 			continue
 		}
 		linkId := link.LinkRouteTableId
-		log.Printf("Unlinking route table %s (link %s)... ", routeTableId, linkId)
+		ContextLogger(ctx).Printf("Unlinking route table %s (link %s)... ", routeTableId, linkId)
 		unlinkOps := osc.UnlinkRouteTableRequest{
 			LinkRouteTableId: link.LinkRouteTableId,
 		}
 		_, err := provider.client.UnlinkRouteTable(ctx, unlinkOps)
 		if err != nil {
-			log.Printf(
+			ContextLogger(ctx).Printf(
 				"Error while unlinking route table %s (links %s): %v\n",
 				routeTableId,
 				linkId,
@@ -724,7 +974,7 @@ func (provider *OutscaleOAPI) unlinkRouteTable(ctx context.Context, routeTableId
 			)
 			return err
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
 	}
 	return nil
@@ -739,23 +989,27 @@ func (provider *OutscaleOAPI) isMainRouteTable(routeTable *osc.RouteTable) bool
 	return false
 }
 
-func (provider *OutscaleOAPI) deleteRouteTables(ctx context.Context, routeTables []Object) {
+func (provider *OutscaleOAPI) deleteRouteTables(ctx context.Context, routeTables []Object) []DeleteResult {
 	if len(routeTables) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(routeTables))
 	for _, routeTable := range routeTables {
-		if provider.unlinkRouteTable(ctx, routeTable) != nil {
+		if err := provider.unlinkRouteTable(ctx, routeTable.Id); err != nil {
+			results = append(results, newDeleteResult(routeTable, err))
 			continue
 		}
-		log.Printf("Deleting route table %s... ", routeTable)
-		deletionOpts := osc.DeleteRouteTableRequest{RouteTableId: routeTable}
+		ContextLogger(ctx).Printf("Deleting route table %s... ", routeTable)
+		deletionOpts := osc.DeleteRouteTableRequest{RouteTableId: routeTable.Id}
 		_, err := provider.client.DeleteRouteTable(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting route table: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting route table: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(routeTable, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readInternetServices(ctx context.Context) ([]Object, error) {
@@ -768,83 +1022,100 @@ func (provider *OutscaleOAPI) readInternetServices(ctx context.Context) ([]Objec
 		return nil, fmt.Errorf("read internet service: %w", getErrorInfo(err))
 	}
 	for i, internetService := range *read.InternetServices {
-		internetServices = append(internetServices, internetService.InternetServiceId)
-		provider.cache.internetServices[internetService.InternetServiceId] = &(*read.InternetServices)[i]
+		object := provider.newObject(internetService.InternetServiceId, internetService.Tags)
+		object.SetAttribute("state", internetService.State)
+		object.SetAttribute("net_id", internetService.NetId)
+		internetServices = append(internetServices, object)
+		provider.cache.internetServices.set(internetService.InternetServiceId, &(*read.InternetServices)[i])
 	}
 	return internetServices, nil
 }
 
 func (provider *OutscaleOAPI) unlinkInternetSevice(ctx context.Context, internetServiceId string) error {
-	internetService := provider.cache.internetServices[internetServiceId]
+	internetService := provider.cache.internetServices.get(internetServiceId)
 	if internetService == nil || internetService.NetId == "" {
 		return nil
 	}
-	log.Printf("Unlinking internet service %s... ", internetServiceId)
+	ContextLogger(ctx).Printf("Unlinking internet service %s... ", internetServiceId)
 	unlinkOps := osc.UnlinkInternetServiceRequest{
 		InternetServiceId: internetServiceId,
 		NetId:             internetService.NetId,
 	}
 	_, err := provider.client.UnlinkInternetService(ctx, unlinkOps)
 	if err != nil {
-		log.Printf("Error while unlinking internet service: %v\n", getErrorInfo(err))
+		ContextLogger(ctx).Printf("Error while unlinking internet service: %v\n", getErrorInfo(err))
 		return err
 	} else {
-		log.Println("OK")
+		ContextLogger(ctx).Println("OK")
 	}
 	return nil
 }
 
-func (provider *OutscaleOAPI) deleteInternetServices(ctx context.Context, internetServices []Object) {
+func (provider *OutscaleOAPI) deleteInternetServices(ctx context.Context, internetServices []Object) []DeleteResult {
 	if len(internetServices) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(internetServices))
 	for _, internetService := range internetServices {
-		if provider.unlinkInternetSevice(ctx, internetService) != nil {
+		if err := provider.unlinkInternetSevice(ctx, internetService.Id); err != nil {
+			results = append(results, newDeleteResult(internetService, err))
 			continue
 		}
-		log.Printf("Deleting internet service %s... ", internetService)
-		deletionOpts := osc.DeleteInternetServiceRequest{InternetServiceId: internetService}
+		ContextLogger(ctx).Printf("Deleting internet service %s... ", internetService)
+		deletionOpts := osc.DeleteInternetServiceRequest{InternetServiceId: internetService.Id}
 		_, err := provider.client.DeleteInternetService(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting internet service: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting internet service: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(internetService, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readSubnets(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readSubnets(ctx context.Context, ids *[]string) ([]Object, error) {
 	subnets := make([]Object, 0)
-	read, err := provider.client.ReadSubnets(ctx, osc.ReadSubnetsRequest{})
+	read, err := provider.client.ReadSubnets(ctx, osc.ReadSubnetsRequest{
+		Filters: &osc.FiltersSubnet{SubnetIds: ids},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("read subnets: %w", getErrorInfo(err))
 	}
 	for _, subnet := range *read.Subnets {
-		subnets = append(subnets, subnet.SubnetId)
+		object := provider.newObject(subnet.SubnetId, subnet.Tags)
+		object.SetAttribute("state", string(subnet.State))
+		object.SetAttribute("net_id", subnet.NetId)
+		object.SetAttribute("subregion", subnet.SubregionName)
+		subnets = append(subnets, object)
 	}
 	return subnets, nil
 }
 
-func (provider *OutscaleOAPI) deleteSubnets(ctx context.Context, subnets []Object) {
+func (provider *OutscaleOAPI) deleteSubnets(ctx context.Context, subnets []Object) []DeleteResult {
 	if len(subnets) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(subnets))
 	for _, subnet := range subnets {
-		log.Printf("Deleting subnet %s... ", subnet)
-		deletionOpts := osc.DeleteSubnetRequest{SubnetId: subnet}
+		ContextLogger(ctx).Printf("Deleting subnet %s... ", subnet)
+		deletionOpts := osc.DeleteSubnetRequest{SubnetId: subnet.Id}
 		_, err := provider.client.DeleteSubnet(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting subnet: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting subnet: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(subnet, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readNets(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readNets(ctx context.Context, ids *[]string) ([]Object, error) {
 	nets := make([]Object, 0)
 	read, err := provider.client.ReadNets(ctx, osc.ReadNetsRequest{
 		Filters: &osc.FiltersNet{
+			NetIds: ids,
 			States: &[]osc.NetState{"pending", "available"}, // skipping deleting
 		},
 	})
@@ -852,28 +1123,36 @@ func (provider *OutscaleOAPI) readNets(ctx context.Context) ([]Object, error) {
 		return nil, fmt.Errorf("read nets: %w", getErrorInfo(err))
 	}
 	for _, net := range *read.Nets {
-		nets = append(nets, net.NetId)
+		object := provider.newObject(net.NetId, net.Tags)
+		object.SetAttribute("state", string(net.State))
+		object.SetAttribute("ip_range", net.IpRange)
+		nets = append(nets, object)
 	}
 	return nets, nil
 }
 
-func (provider *OutscaleOAPI) deleteNets(ctx context.Context, nets []Object) {
+func (provider *OutscaleOAPI) deleteNets(ctx context.Context, nets []Object) []DeleteResult {
 	if len(nets) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(nets))
 	for _, net := range nets {
-		log.Printf("Deleting net %s... ", net)
-		deletionOpts := osc.DeleteNetRequest{NetId: net}
+		ContextLogger(ctx).Printf("Deleting net %s... ", net)
+		deletionOpts := osc.DeleteNetRequest{NetId: net.Id}
 		_, err := provider.client.DeleteNet(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting net: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting net: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(net, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readAccountId(ctx context.Context) (*string, error) {
+	provider.cache.accountIdMutex.Lock()
+	defer provider.cache.accountIdMutex.Unlock()
 	if provider.cache.accountId == nil {
 		read, err := provider.client.ReadAccounts(
 			ctx,
@@ -883,7 +1162,7 @@ func (provider *OutscaleOAPI) readAccountId(ctx context.Context) (*string, error
 			return nil, fmt.Errorf("read vms: %w", getErrorInfo(err))
 		}
 		if len(*read.Accounts) == 0 {
-			log.Println("Error while reading account: no account listed")
+			ContextLogger(ctx).Println("Error while reading account: no account listed")
 			return nil, err
 		}
 		provider.cache.accountId = (*read.Accounts)[0].AccountId
@@ -891,7 +1170,7 @@ func (provider *OutscaleOAPI) readAccountId(ctx context.Context) (*string, error
 	return provider.cache.accountId, nil
 }
 
-func (provider *OutscaleOAPI) readImages(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readImages(ctx context.Context, ids *[]string) ([]Object, error) {
 	images := make([]Object, 0)
 	accountId, err := provider.readAccountId(ctx)
 	if err != nil {
@@ -902,6 +1181,7 @@ func (provider *OutscaleOAPI) readImages(ctx context.Context) ([]Object, error)
 	read, err := provider.client.ReadImages(ctx, osc.ReadImagesRequest{
 		Filters: &osc.FiltersImage{
 			AccountIds: &accountIds,
+			ImageIds:   ids,
 		},
 	})
 	if err != nil {
@@ -909,28 +1189,37 @@ func (provider *OutscaleOAPI) readImages(ctx context.Context) ([]Object, error)
 		return nil, fmt.Errorf("read images: %w", err)
 	}
 	for _, image := range *read.Images {
-		images = append(images, image.ImageId)
+		object := provider.newObject(image.ImageId, image.Tags)
+		if image.ImageName != nil {
+			object.Name = *image.ImageName
+		}
+		object.SetCreatedAt(image.CreationDate.Time)
+		object.SetAttribute("state", string(image.State))
+		images = append(images, object)
 	}
 	return images, nil
 }
 
-func (provider *OutscaleOAPI) deleteImages(ctx context.Context, images []Object) {
+func (provider *OutscaleOAPI) deleteImages(ctx context.Context, images []Object) []DeleteResult {
 	if len(images) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(images))
 	for _, image := range images {
-		log.Printf("Deleting image %s... ", image)
-		deletionOpts := osc.DeleteImageRequest{ImageId: image}
+		ContextLogger(ctx).Printf("Deleting image %s... ", image)
+		deletionOpts := osc.DeleteImageRequest{ImageId: image.Id}
 		_, err := provider.client.DeleteImage(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting image: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting image: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(image, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readSnapshots(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readSnapshots(ctx context.Context, ids *[]string) ([]Object, error) {
 	snapshots := make([]Object, 0)
 	accountId, err := provider.readAccountId(ctx)
 	if err != nil {
@@ -940,7 +1229,8 @@ func (provider *OutscaleOAPI) readSnapshots(ctx context.Context) ([]Object, erro
 	accountIds = append(accountIds, *accountId)
 	read, err := provider.client.ReadSnapshots(ctx, osc.ReadSnapshotsRequest{
 		Filters: &osc.FiltersSnapshot{
-			AccountIds: &accountIds,
+			AccountIds:  &accountIds,
+			SnapshotIds: ids,
 			States: &[]osc.SnapshotState{
 				"in-queue", "pending", "completed", "error", // skipping deleting
 			},
@@ -950,25 +1240,36 @@ func (provider *OutscaleOAPI) readSnapshots(ctx context.Context) ([]Object, erro
 		return nil, fmt.Errorf("read snapshots: %w", getErrorInfo(err))
 	}
 	for _, snapshot := range *read.Snapshots {
-		snapshots = append(snapshots, snapshot.SnapshotId)
+		var tags []osc.ResourceTag
+		if snapshot.Tags != nil {
+			tags = *snapshot.Tags
+		}
+		object := provider.newObject(snapshot.SnapshotId, tags)
+		object.SetCreatedAt(snapshot.CreationDate.Time)
+		object.SetAttribute("state", string(snapshot.State))
+		object.SetAttribute("volume_id", snapshot.VolumeId)
+		snapshots = append(snapshots, object)
 	}
 	return snapshots, nil
 }
 
-func (provider *OutscaleOAPI) deleteSnapshots(ctx context.Context, snapshots []Object) {
+func (provider *OutscaleOAPI) deleteSnapshots(ctx context.Context, snapshots []Object) []DeleteResult {
 	if len(snapshots) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(snapshots))
 	for _, snapshot := range snapshots {
-		log.Printf("Deleting snapshot %s... ", snapshot)
-		deletionOpts := osc.DeleteSnapshotRequest{SnapshotId: snapshot}
+		ContextLogger(ctx).Printf("Deleting snapshot %s... ", snapshot)
+		deletionOpts := osc.DeleteSnapshotRequest{SnapshotId: snapshot.Id}
 		_, err := provider.client.DeleteSnapshot(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting snapshot: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting snapshot: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(snapshot, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readVpnConnections(ctx context.Context) ([]Object, error) {
@@ -987,25 +1288,32 @@ func (provider *OutscaleOAPI) readVpnConnections(ctx context.Context) ([]Object,
 		return nil, fmt.Errorf("read vpn connections: %w", getErrorInfo(err))
 	}
 	for _, vpnConnection := range *read.VpnConnections {
-		vpnConnections = append(vpnConnections, vpnConnection.VpnConnectionId)
+		object := provider.newObject(vpnConnection.VpnConnectionId, vpnConnection.Tags)
+		object.SetAttribute("state", string(vpnConnection.State))
+		object.SetAttribute("client_gateway_id", vpnConnection.ClientGatewayId)
+		object.SetAttribute("virtual_gateway_id", vpnConnection.VirtualGatewayId)
+		vpnConnections = append(vpnConnections, object)
 	}
 	return vpnConnections, nil
 }
 
-func (provider *OutscaleOAPI) deleteVpnConnections(ctx context.Context, vpnConnections []Object) {
+func (provider *OutscaleOAPI) deleteVpnConnections(ctx context.Context, vpnConnections []Object) []DeleteResult {
 	if len(vpnConnections) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(vpnConnections))
 	for _, vpnConnection := range vpnConnections {
-		log.Printf("Deleting vpn connection %s... ", vpnConnection)
-		deletionOpts := osc.DeleteVpnConnectionRequest{VpnConnectionId: vpnConnection}
+		ContextLogger(ctx).Printf("Deleting vpn connection %s... ", vpnConnection)
+		deletionOpts := osc.DeleteVpnConnectionRequest{VpnConnectionId: vpnConnection.Id}
 		_, err := provider.client.DeleteVpnConnection(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting vpn connection: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting vpn connection: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(vpnConnection, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readVirtualGateways(ctx context.Context) ([]Object, error) {
@@ -1014,7 +1322,7 @@ func (provider *OutscaleOAPI) readVirtualGateways(ctx context.Context) ([]Object
 		ctx,
 		osc.ReadVirtualGatewaysRequest{
 			Filters: &osc.FiltersVirtualGateway{
-				States: &[]string{
+				States: &[]osc.VirtualGatewayState{
 					"pending", "available", // skipping deleting, deleted
 				},
 			},
@@ -1024,25 +1332,30 @@ func (provider *OutscaleOAPI) readVirtualGateways(ctx context.Context) ([]Object
 		return nil, fmt.Errorf("read virtual gateways: %w", getErrorInfo(err))
 	}
 	for _, virtualGateway := range *read.VirtualGateways {
-		virtualGateways = append(virtualGateways, virtualGateway.VirtualGatewayId)
+		object := provider.newObject(virtualGateway.VirtualGatewayId, virtualGateway.Tags)
+		object.SetAttribute("state", string(virtualGateway.State))
+		virtualGateways = append(virtualGateways, object)
 	}
 	return virtualGateways, nil
 }
 
-func (provider *OutscaleOAPI) deleteVirtualGateways(ctx context.Context, virtualGateways []Object) {
+func (provider *OutscaleOAPI) deleteVirtualGateways(ctx context.Context, virtualGateways []Object) []DeleteResult {
 	if len(virtualGateways) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(virtualGateways))
 	for _, virtualGateway := range virtualGateways {
-		log.Printf("Deleting virtual gateway %s... ", virtualGateway)
-		deletionOpts := osc.DeleteVirtualGatewayRequest{VirtualGatewayId: virtualGateway}
+		ContextLogger(ctx).Printf("Deleting virtual gateway %s... ", virtualGateway)
+		deletionOpts := osc.DeleteVirtualGatewayRequest{VirtualGatewayId: virtualGateway.Id}
 		_, err := provider.client.DeleteVirtualGateway(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting virtual gateway: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting virtual gateway: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(virtualGateway, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readClientGateways(ctx context.Context) ([]Object, error) {
@@ -1061,43 +1374,58 @@ func (provider *OutscaleOAPI) readClientGateways(ctx context.Context) ([]Object,
 		return nil, fmt.Errorf("read client gateways: %w", getErrorInfo(err))
 	}
 	for _, clientGateway := range *read.ClientGateways {
-		clientGateways = append(clientGateways, clientGateway.ClientGatewayId)
+		object := provider.newObject(clientGateway.ClientGatewayId, clientGateway.Tags)
+		object.SetAttribute("state", string(clientGateway.State))
+		clientGateways = append(clientGateways, object)
 	}
 	return clientGateways, nil
 }
 
-func (provider *OutscaleOAPI) deleteClientGateways(ctx context.Context, clientGateways []Object) {
+func (provider *OutscaleOAPI) deleteClientGateways(ctx context.Context, clientGateways []Object) []DeleteResult {
 	if len(clientGateways) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(clientGateways))
 	for _, clientGateway := range clientGateways {
-		log.Printf("Deleting client gateway %s... ", clientGateway)
-		deletionOpts := osc.DeleteClientGatewayRequest{ClientGatewayId: clientGateway}
+		ContextLogger(ctx).Printf("Deleting client gateway %s... ", clientGateway)
+		deletionOpts := osc.DeleteClientGatewayRequest{ClientGatewayId: clientGateway.Id}
 		_, err := provider.client.DeleteClientGateway(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting client gateway: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting client gateway: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(clientGateway, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOAPI) readNics(ctx context.Context) ([]Object, error) {
+func (provider *OutscaleOAPI) readNics(ctx context.Context, ids *[]string) ([]Object, error) {
 	nics := make([]Object, 0)
-	read, err := provider.client.ReadNics(ctx, osc.ReadNicsRequest{})
+	read, err := provider.client.ReadNics(ctx, osc.ReadNicsRequest{
+		Filters: &osc.FiltersNic{NicIds: ids},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("read nics: %w", getErrorInfo(err))
 	}
 	for i, nic := range *read.Nics {
-		nics = append(nics, nic.NicId)
-		provider.cache.nics[nic.NicId] = &(*read.Nics)[i]
+		object := provider.newObject(nic.NicId, nic.Tags)
+		object.SetAttribute("state", string(nic.State))
+		object.SetAttribute("net_id", nic.NetId)
+		object.SetAttribute("subnet_id", nic.SubnetId)
+		nics = append(nics, object)
+		provider.cache.nics.set(nic.NicId, &(*read.Nics)[i])
 	}
 	return nics, nil
 }
 
-func (provider *OutscaleOAPI) unlinkNics(ctx context.Context, nics []Object) {
-	for _, nicId := range nics {
-		nic := provider.cache.nics[nicId]
+// unlinkNics detaches nics from their VM, except when the VM is protected. It
+// returns the failed results of nics which must not be deleted.
+func (provider *OutscaleOAPI) unlinkNics(ctx context.Context, nics []Object) map[string]DeleteResult {
+	failed := make(map[string]DeleteResult)
+	for _, nicObject := range nics {
+		nicId := nicObject.Id
+		nic := provider.cache.nics.get(nicId)
 		if nic == nil {
 			continue
 		}
@@ -1109,32 +1437,44 @@ func (provider *OutscaleOAPI) unlinkNics(ctx context.Context, nics []Object) {
 		if nic.LinkNic == nil {
 			continue
 		}
-		log.Printf("Unlinking nic %s... ", nicId)
+		if err := provider.checkLinked(ctx, typeVm, nic.LinkNic.VmId); err != nil {
+			failed[nicId] = newDeleteResult(nicObject, err)
+			continue
+		}
+		ContextLogger(ctx).Printf("Unlinking nic %s... ", nicId)
 		unlinkOpts := osc.UnlinkNicRequest{LinkNicId: nic.LinkNic.LinkNicId}
 		_, err := provider.client.UnlinkNic(ctx, unlinkOpts)
 		if err != nil {
-			log.Printf("Error while unlinking nic: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while unlinking nic: %v\n", getErrorInfo(err))
 			continue
 		}
-		log.Println("OK")
+		ContextLogger(ctx).Println("OK")
 	}
+	return failed
 }
 
-func (provider *OutscaleOAPI) deleteNics(ctx context.Context, nics []Object) {
+func (provider *OutscaleOAPI) deleteNics(ctx context.Context, nics []Object) []DeleteResult {
 	if len(nics) == 0 {
-		return
+		return nil
 	}
-	provider.unlinkNics(ctx, nics)
-	for _, nicId := range nics {
-		log.Printf("Deleting nic %s... ", nicId)
-		deletionOpts := osc.DeleteNicRequest{NicId: nicId}
+	failed := provider.unlinkNics(ctx, nics)
+	results := make([]DeleteResult, 0, len(nics))
+	for _, nic := range nics {
+		if result, found := failed[nic.Id]; found {
+			results = append(results, result)
+			continue
+		}
+		ContextLogger(ctx).Printf("Deleting nic %s... ", nic)
+		deletionOpts := osc.DeleteNicRequest{NicId: nic.Id}
 		_, err := provider.client.DeleteNic(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting nic: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting nic: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(nic, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readAccessKeys(ctx context.Context) ([]Object, error) {
@@ -1152,26 +1492,36 @@ func (provider *OutscaleOAPI) readAccessKeys(ctx context.Context) ([]Object, err
 			if accessKey.ExpirationDate != nil && time.Now().After(accessKey.ExpirationDate.Time) {
 				continue
 			}
-			accessKeys = append(accessKeys, *accessKey.AccessKeyId)
+			object := NewObject(*accessKey.AccessKeyId)
+			if accessKey.CreationDate != nil {
+				object.SetCreatedAt(accessKey.CreationDate.Time)
+			}
+			if accessKey.ExpirationDate != nil {
+				object.SetAttribute("expiration_date", accessKey.ExpirationDate.String())
+			}
+			accessKeys = append(accessKeys, object)
 		}
 	}
 	return accessKeys, nil
 }
 
-func (provider *OutscaleOAPI) deleteAccessKeys(ctx context.Context, accessKeys []Object) {
+func (provider *OutscaleOAPI) deleteAccessKeys(ctx context.Context, accessKeys []Object) []DeleteResult {
 	if len(accessKeys) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(accessKeys))
 	for _, accessKey := range accessKeys {
-		log.Printf("Deleting access key %s... ", accessKey)
-		deletionOpts := osc.DeleteAccessKeyRequest{AccessKeyId: accessKey}
+		ContextLogger(ctx).Printf("Deleting access key %s... ", accessKey)
+		deletionOpts := osc.DeleteAccessKeyRequest{AccessKeyId: accessKey.Id}
 		_, err := provider.client.DeleteAccessKey(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting access key: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting access key: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(accessKey, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readNetAccessPoints(ctx context.Context) ([]Object, error) {
@@ -1190,25 +1540,32 @@ func (provider *OutscaleOAPI) readNetAccessPoints(ctx context.Context) ([]Object
 		return nil, fmt.Errorf("read net access points: %w", getErrorInfo(err))
 	}
 	for _, netAccessPoint := range *read.NetAccessPoints {
-		netAccessPoints = append(netAccessPoints, netAccessPoint.NetAccessPointId)
+		object := provider.newObject(netAccessPoint.NetAccessPointId, netAccessPoint.Tags)
+		object.SetAttribute("state", string(netAccessPoint.State))
+		object.SetAttribute("net_id", netAccessPoint.NetId)
+		object.SetAttribute("service_name", netAccessPoint.ServiceName)
+		netAccessPoints = append(netAccessPoints, object)
 	}
 	return netAccessPoints, nil
 }
 
-func (provider *OutscaleOAPI) deleteNetAccessPoints(ctx context.Context, netAccessPoints []Object) {
+func (provider *OutscaleOAPI) deleteNetAccessPoints(ctx context.Context, netAccessPoints []Object) []DeleteResult {
 	if len(netAccessPoints) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(netAccessPoints))
 	for _, netAccessPoint := range netAccessPoints {
-		log.Printf("Deleting net access point %s... ", netAccessPoint)
-		deletionOpts := osc.DeleteNetAccessPointRequest{NetAccessPointId: netAccessPoint}
+		ContextLogger(ctx).Printf("Deleting net access point %s... ", netAccessPoint)
+		deletionOpts := osc.DeleteNetAccessPointRequest{NetAccessPointId: netAccessPoint.Id}
 		_, err := provider.client.DeleteNetAccessPoint(ctx, deletionOpts)
 		if err != nil {
-			log.Print("Error while deleting net access point: ")
+			ContextLogger(ctx).Print("Error while deleting net access point: ")
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(netAccessPoint, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readNetPeerings(ctx context.Context) ([]Object, error) {
@@ -1227,25 +1584,30 @@ func (provider *OutscaleOAPI) readNetPeerings(ctx context.Context) ([]Object, er
 		return nil, fmt.Errorf("read net peerings: %w", getErrorInfo(err))
 	}
 	for _, netPeering := range *read.NetPeerings {
-		netPeerings = append(netPeerings, netPeering.NetPeeringId)
+		object := provider.newObject(netPeering.NetPeeringId, netPeering.Tags)
+		object.SetAttribute("state", string(netPeering.State.Name))
+		netPeerings = append(netPeerings, object)
 	}
 	return netPeerings, nil
 }
 
-func (provider *OutscaleOAPI) deleteNetPeerings(ctx context.Context, netPeerings []Object) {
+func (provider *OutscaleOAPI) deleteNetPeerings(ctx context.Context, netPeerings []Object) []DeleteResult {
 	if len(netPeerings) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(netPeerings))
 	for _, netPeering := range netPeerings {
-		log.Printf("Deleting net peering %s... ", netPeering)
-		deletionOpts := osc.DeleteNetPeeringRequest{NetPeeringId: netPeering}
+		ContextLogger(ctx).Printf("Deleting net peering %s... ", netPeering)
+		deletionOpts := osc.DeleteNetPeeringRequest{NetPeeringId: netPeering.Id}
 		_, err := provider.client.DeleteNetPeering(ctx, deletionOpts)
 		if err != nil {
-			log.Print("Error while deleting net peering: %w", err)
+			ContextLogger(ctx).Print("Error while deleting net peering: %w", err)
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(netPeering, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readUsers(ctx context.Context) ([]Object, error) {
@@ -1255,25 +1617,36 @@ func (provider *OutscaleOAPI) readUsers(ctx context.Context) ([]Object, error) {
 		return nil, fmt.Errorf("read users: %w", getErrorInfo(err))
 	}
 	for _, user := range *read.Users {
-		users = append(users, *user.UserName)
+		object := NewObject(*user.UserName)
+		object.Name = *user.UserName
+		if user.CreationDate != nil {
+			object.SetCreatedAt(user.CreationDate.Time)
+		}
+		if user.UserId != nil {
+			object.SetAttribute("user_id", *user.UserId)
+		}
+		users = append(users, object)
 	}
 	return users, nil
 }
 
-func (provider *OutscaleOAPI) deleteUsers(ctx context.Context, users []Object) {
+func (provider *OutscaleOAPI) deleteUsers(ctx context.Context, users []Object) []DeleteResult {
 	if len(users) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(users))
 	for _, user := range users {
-		log.Printf("Deleting user %s... ", user)
-		deleteOpts := osc.DeleteUserRequest{UserName: user}
+		ContextLogger(ctx).Printf("Deleting user %s... ", user)
+		deleteOpts := osc.DeleteUserRequest{UserName: user.Id}
 		_, err := provider.client.DeleteUser(ctx, deleteOpts)
 		if err != nil {
-			log.Print("Error while deleting user: %w", err)
+			ContextLogger(ctx).Print("Error while deleting user: %w", err)
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(user, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readUserGroups(ctx context.Context) ([]Object, error) {
@@ -1283,25 +1656,36 @@ func (provider *OutscaleOAPI) readUserGroups(ctx context.Context) ([]Object, err
 		return nil, fmt.Errorf("read user groups: %w", getErrorInfo(err))
 	}
 	for _, userGroup := range *read.UserGroups {
-		userGroups = append(userGroups, *userGroup.Name)
+		object := NewObject(*userGroup.Name)
+		object.Name = *userGroup.Name
+		if userGroup.CreationDate != nil {
+			object.SetCreatedAt(userGroup.CreationDate.Time)
+		}
+		if userGroup.UserGroupId != nil {
+			object.SetAttribute("user_group_id", *userGroup.UserGroupId)
+		}
+		userGroups = append(userGroups, object)
 	}
 	return userGroups, nil
 }
 
-func (provider *OutscaleOAPI) deleteUserGroups(ctx context.Context, userGroups []Object) {
+func (provider *OutscaleOAPI) deleteUserGroups(ctx context.Context, userGroups []Object) []DeleteResult {
 	if len(userGroups) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(userGroups))
 	for _, userGroup := range userGroups {
-		log.Printf("Deleting user group %s... ", userGroup)
-		deleteOpts := osc.DeleteUserGroupRequest{UserGroupName: userGroup}
+		ContextLogger(ctx).Printf("Deleting user group %s... ", userGroup)
+		deleteOpts := osc.DeleteUserGroupRequest{UserGroupName: userGroup.Id}
 		_, err := provider.client.DeleteUserGroup(ctx, deleteOpts)
 		if err != nil {
-			log.Print("Error while deleting user group: %w", err)
+			ContextLogger(ctx).Print("Error while deleting user group: %w", err)
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(userGroup, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readUserAccessKeys(ctx context.Context) ([]Object, error) {
@@ -1322,34 +1706,47 @@ func (provider *OutscaleOAPI) readUserAccessKeys(ctx context.Context) ([]Object,
 		}
 		for _, accessKey := range *read.AccessKeys {
 			if *accessKey.State == "ACTIVE" {
-				composed := fmt.Sprintf("%s,%s", *user.UserName, *accessKey.AccessKeyId)
-				accessKeys = append(accessKeys, composed)
+				object := NewObject(fmt.Sprintf("%s,%s", *user.UserName, *accessKey.AccessKeyId))
+				if accessKey.CreationDate != nil {
+					object.SetCreatedAt(accessKey.CreationDate.Time)
+				}
+				object.SetAttribute("user_name", *user.UserName)
+				object.SetAttribute("access_key_id", *accessKey.AccessKeyId)
+				accessKeys = append(accessKeys, object)
 			}
 		}
 	}
 	return accessKeys, nil
 }
 
-func (provider *OutscaleOAPI) deleteUserAccessKeys(ctx context.Context, accessKeys []Object) {
+func (provider *OutscaleOAPI) deleteUserAccessKeys(ctx context.Context, accessKeys []Object) []DeleteResult {
 	if len(accessKeys) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(accessKeys))
 	for _, accessKey := range accessKeys {
-		log.Printf("Deleting user access key %s... ", accessKey)
-		parts := strings.SplitN(string(accessKey), ",", 2)
+		ContextLogger(ctx).Printf("Deleting user access key %s... ", accessKey)
+		parts := strings.SplitN(accessKey.Id, ",", 2)
 		if len(parts) != 2 {
-			log.Printf("Invalid access key format: %s", accessKey)
+			ContextLogger(ctx).Printf("Invalid access key format: %s", accessKey)
+			results = append(results, DeleteFailed(
+				accessKey,
+				DeleteErrorForbidden,
+				fmt.Errorf("invalid access key format: %s", accessKey.Id),
+			))
 			continue
 		}
 
 		deletionOpts := osc.DeleteAccessKeyRequest{AccessKeyId: parts[1], UserName: &parts[0]}
 		_, err := provider.client.DeleteAccessKey(ctx, deletionOpts)
 		if err != nil {
-			log.Printf("Error while deleting user access key: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting user access key: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(accessKey, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readPolicies(ctx context.Context) ([]Object, error) {
@@ -1360,25 +1757,35 @@ func (provider *OutscaleOAPI) readPolicies(ctx context.Context) ([]Object, error
 		return nil, fmt.Errorf("read policies: %w", getErrorInfo(err))
 	}
 	for _, policy := range *read.Policies {
-		policies = append(policies, *policy.Orn)
+		object := NewObject(*policy.Orn)
+		if policy.PolicyName != nil {
+			object.Name = *policy.PolicyName
+		}
+		if policy.CreationDate != nil {
+			object.SetCreatedAt(policy.CreationDate.Time)
+		}
+		policies = append(policies, object)
 	}
 	return policies, nil
 }
 
-func (provider *OutscaleOAPI) deletePolicies(ctx context.Context, policies []Object) {
+func (provider *OutscaleOAPI) deletePolicies(ctx context.Context, policies []Object) []DeleteResult {
 	if len(policies) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(policies))
 	for _, policy := range policies {
-		log.Printf("Deleting policy %s... ", policy)
-		deleteOpts := osc.DeletePolicyRequest{PolicyOrn: policy}
+		ContextLogger(ctx).Printf("Deleting policy %s... ", policy)
+		deleteOpts := osc.DeletePolicyRequest{PolicyOrn: policy.Id}
 		_, err := provider.client.DeletePolicy(ctx, deleteOpts)
 		if err != nil {
-			log.Print("Error while deleting policy: %w", err)
+			ContextLogger(ctx).Print("Error while deleting policy: %w", err)
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(policy, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readPolicyLinks(ctx context.Context) ([]Object, error) {
@@ -1400,46 +1807,53 @@ func (provider *OutscaleOAPI) readPolicyLinks(ctx context.Context) ([]Object, er
 			return nil, fmt.Errorf("read policy links: %w", getErrorInfo(err))
 		}
 		for _, policyLink := range *read.PolicyEntities.Groups {
-			policyLinks = append(
-				policyLinks,
-				fmt.Sprintf("GROUP,%s,%s", *policy.Orn, *policyLink.Name),
-			)
+			object := NewObject(fmt.Sprintf("GROUP,%s,%s", *policy.Orn, *policyLink.Name))
+			object.SetAttribute("policy_orn", *policy.Orn)
+			object.SetAttribute("user_group_name", *policyLink.Name)
+			policyLinks = append(policyLinks, object)
 		}
 		for _, policyLink := range *read.PolicyEntities.Users {
-			policyLinks = append(
-				policyLinks,
-				fmt.Sprintf("USER,%s,%s", *policy.Orn, *policyLink.Name),
-			)
+			object := NewObject(fmt.Sprintf("USER,%s,%s", *policy.Orn, *policyLink.Name))
+			object.SetAttribute("policy_orn", *policy.Orn)
+			object.SetAttribute("user_name", *policyLink.Name)
+			policyLinks = append(policyLinks, object)
 		}
 	}
 	return policyLinks, nil
 }
 
-func (provider *OutscaleOAPI) deletePolicyLinks(ctx context.Context, policyLinks []Object) {
+func (provider *OutscaleOAPI) deletePolicyLinks(ctx context.Context, policyLinks []Object) []DeleteResult {
 	if len(policyLinks) == 0 {
-		return
+		return nil
 	}
 
+	results := make([]DeleteResult, 0, len(policyLinks))
 	for _, policylink := range policyLinks {
-		log.Printf("Deleting policy link %s... ", policylink)
-		parts := strings.SplitN(string(policylink), ",", 3)
+		ContextLogger(ctx).Printf("Deleting policy link %s... ", policylink)
+		parts := strings.SplitN(policylink.Id, ",", 3)
 		if len(parts) != 3 {
-			log.Printf("Invalid policy link format: %s", policylink)
+			ContextLogger(ctx).Printf("Invalid policy link format: %s", policylink)
+			results = append(results, DeleteFailed(
+				policylink,
+				DeleteErrorForbidden,
+				fmt.Errorf("invalid policy link format: %s", policylink.Id),
+			))
 			continue
 		}
 		linkType := parts[0]
 		policyOrn := parts[1]
 		linkName := parts[2]
 
+		var err error
 		switch linkType {
 		case "USER":
 			deleteOpts := osc.UnlinkPolicyRequest{
 				PolicyOrn: policyOrn,
 				UserName:  linkName,
 			}
-			_, err := provider.client.UnlinkPolicy(ctx, deleteOpts)
+			_, err = provider.client.UnlinkPolicy(ctx, deleteOpts)
 			if err != nil {
-				log.Print("Error while unlinking policy: %w", err)
+				ContextLogger(ctx).Print("Error while unlinking policy: %w", err)
 			}
 
 		case "GROUP":
@@ -1447,15 +1861,19 @@ func (provider *OutscaleOAPI) deletePolicyLinks(ctx context.Context, policyLinks
 				PolicyOrn:     policyOrn,
 				UserGroupName: linkName,
 			}
-			_, err := provider.client.UnlinkManagedPolicyFromUserGroup(
+			_, err = provider.client.UnlinkManagedPolicyFromUserGroup(
 				ctx,
 				deleteOpts,
 			)
 			if err != nil {
-				log.Print("Error while unlinking policy: %w", err)
+				ContextLogger(ctx).Print("Error while unlinking policy: %w", err)
 			}
+		default:
+			err = fmt.Errorf("unknown policy link type: %s", linkType)
 		}
+		results = append(results, newDeleteResult(policylink, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readPolicyVersions(ctx context.Context) ([]Object, error) {
@@ -1480,25 +1898,34 @@ func (provider *OutscaleOAPI) readPolicyVersions(ctx context.Context) ([]Object,
 				continue
 			}
 
-			policyVersions = append(
-				policyVersions,
-				fmt.Sprintf("%s,%s", *policy.Orn, *policyVersion.VersionId),
-			)
+			object := NewObject(fmt.Sprintf("%s,%s", *policy.Orn, *policyVersion.VersionId))
+			if policyVersion.CreationDate != nil {
+				object.SetCreatedAt(policyVersion.CreationDate.Time)
+			}
+			object.SetAttribute("policy_orn", *policy.Orn)
+			object.SetAttribute("version_id", *policyVersion.VersionId)
+			policyVersions = append(policyVersions, object)
 		}
 	}
 	return policyVersions, nil
 }
 
-func (provider *OutscaleOAPI) deletePolicyVersions(ctx context.Context, policyVersions []Object) {
+func (provider *OutscaleOAPI) deletePolicyVersions(ctx context.Context, policyVersions []Object) []DeleteResult {
 	if len(policyVersions) == 0 {
-		return
+		return nil
 	}
 
+	results := make([]DeleteResult, 0, len(policyVersions))
 	for _, policyVersion := range policyVersions {
-		log.Printf("Deleting policy version %s... ", policyVersion)
-		parts := strings.SplitN(string(policyVersion), ",", 2)
+		ContextLogger(ctx).Printf("Deleting policy version %s... ", policyVersion)
+		parts := strings.SplitN(policyVersion.Id, ",", 2)
 		if len(parts) != 2 {
-			log.Printf("Invalid policy version format: %s", policyVersion)
+			ContextLogger(ctx).Printf("Invalid policy version format: %s", policyVersion)
+			results = append(results, DeleteFailed(
+				policyVersion,
+				DeleteErrorForbidden,
+				fmt.Errorf("invalid policy version format: %s", policyVersion.Id),
+			))
 			continue
 		}
 
@@ -1508,9 +1935,11 @@ func (provider *OutscaleOAPI) deletePolicyVersions(ctx context.Context, policyVe
 		}
 		_, err := provider.client.DeletePolicyVersion(ctx, deleteOpts)
 		if err != nil {
-			log.Print("Error while deleting policy version: %w", err)
+			ContextLogger(ctx).Print("Error while deleting policy version: %w", err)
 		}
+		results = append(results, newDeleteResult(policyVersion, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readFlexibleGpus(ctx context.Context) ([]Object, error) {
@@ -1521,15 +1950,26 @@ func (provider *OutscaleOAPI) readFlexibleGpus(ctx context.Context) ([]Object, e
 		return nil, fmt.Errorf("read flexible gpus: %w", getErrorInfo(err))
 	}
 	for i, gpu := range *read.FlexibleGpus {
-		flexibleGpus = append(flexibleGpus, gpu.FlexibleGpuId)
-		provider.cache.flexibleGpus[gpu.FlexibleGpuId] = &(*read.FlexibleGpus)[i]
+		object := NewObject(gpu.FlexibleGpuId)
+		object.Region = provider.region
+		if gpu.Tags != nil {
+			object.Tags = make(map[string]string)
+			for _, tag := range *gpu.Tags {
+				object.Tags[tag.Key] = tag.Value
+			}
+			object.Name = object.Tags["Name"]
+		}
+		object.SetAttribute("state", string(gpu.State))
+		object.SetAttribute("model_name", gpu.ModelName)
+		flexibleGpus = append(flexibleGpus, object)
+		provider.cache.flexibleGpus.set(gpu.FlexibleGpuId, &(*read.FlexibleGpus)[i])
 	}
 	return flexibleGpus, nil
 }
 
 func (provider *OutscaleOAPI) unlinkFlexibleGpus(ctx context.Context, flexibleGpus []Object) {
 	for _, gpuObj := range flexibleGpus {
-		gpu := provider.cache.flexibleGpus[gpuObj]
+		gpu := provider.cache.flexibleGpus.get(gpuObj.Id)
 		if gpu == nil {
 			continue
 		}
@@ -1538,32 +1978,35 @@ func (provider *OutscaleOAPI) unlinkFlexibleGpus(ctx context.Context, flexibleGp
 		default:
 			continue
 		}
-		log.Printf("Unlinking flexible gpu %s... ", gpu.FlexibleGpuId)
+		ContextLogger(ctx).Printf("Unlinking flexible gpu %s... ", gpu.FlexibleGpuId)
 		unlinkOpts := osc.UnlinkFlexibleGpuRequest{FlexibleGpuId: gpu.FlexibleGpuId}
 		_, err := provider.client.UnlinkFlexibleGpu(ctx, unlinkOpts)
 		if err != nil {
-			log.Printf("Error while unlinking flexible gpu: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while unlinking flexible gpu: %v\n", getErrorInfo(err))
 			continue
 		}
-		log.Println("OK")
+		ContextLogger(ctx).Println("OK")
 	}
 }
 
-func (provider *OutscaleOAPI) deleteFlexibleGpus(ctx context.Context, flexibleGpus []Object) {
+func (provider *OutscaleOAPI) deleteFlexibleGpus(ctx context.Context, flexibleGpus []Object) []DeleteResult {
 	if len(flexibleGpus) == 0 {
-		return
+		return nil
 	}
 	provider.unlinkFlexibleGpus(ctx, flexibleGpus)
+	results := make([]DeleteResult, 0, len(flexibleGpus))
 	for _, gpu := range flexibleGpus {
-		log.Printf("Releasing flexible gpu %s... ", gpu)
-		deleteOpts := osc.DeleteFlexibleGpuRequest{FlexibleGpuId: gpu}
+		ContextLogger(ctx).Printf("Releasing flexible gpu %s... ", gpu)
+		deleteOpts := osc.DeleteFlexibleGpuRequest{FlexibleGpuId: gpu.Id}
 		_, err := provider.client.DeleteFlexibleGpu(ctx, deleteOpts)
 		if err != nil {
-			log.Print("Error while deleting flexible gpu: %w", err)
+			ContextLogger(ctx).Print("Error while deleting flexible gpu: %w", err)
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(gpu, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readCas(ctx context.Context) ([]Object, error) {
@@ -1573,25 +2016,35 @@ func (provider *OutscaleOAPI) readCas(ctx context.Context) ([]Object, error) {
 		return nil, fmt.Errorf("read cas: %w", getErrorInfo(err))
 	}
 	for _, ca := range *read.Cas {
-		cas = append(cas, *ca.CaId)
+		object := NewObject(*ca.CaId)
+		if ca.Description != nil {
+			object.Name = *ca.Description
+		}
+		if ca.CaFingerprint != nil {
+			object.SetAttribute("fingerprint", *ca.CaFingerprint)
+		}
+		cas = append(cas, object)
 	}
 	return cas, nil
 }
 
-func (provider *OutscaleOAPI) deleteCas(ctx context.Context, cas []Object) {
+func (provider *OutscaleOAPI) deleteCas(ctx context.Context, cas []Object) []DeleteResult {
 	if len(cas) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(cas))
 	for _, ca := range cas {
-		log.Printf("Deleting CA %s... ", ca)
-		deleteOpts := osc.DeleteCaRequest{CaId: ca}
+		ContextLogger(ctx).Printf("Deleting CA %s... ", ca)
+		deleteOpts := osc.DeleteCaRequest{CaId: ca.Id}
 		_, err := provider.client.DeleteCa(ctx, deleteOpts)
 		if err != nil {
-			log.Printf("Error while deleting CA: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting CA: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(ca, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readServerCertificates(ctx context.Context) ([]Object, error) {
@@ -1601,25 +2054,36 @@ func (provider *OutscaleOAPI) readServerCertificates(ctx context.Context) ([]Obj
 		return nil, fmt.Errorf("read server certificates: %w", getErrorInfo(err))
 	}
 	for _, cert := range *read.ServerCertificates {
-		serverCertificates = append(serverCertificates, *cert.Name)
+		object := NewObject(*cert.Name)
+		object.Name = *cert.Name
+		if cert.UploadDate != nil {
+			object.SetCreatedAt(cert.UploadDate.Time)
+		}
+		if cert.ExpirationDate != nil {
+			object.SetAttribute("expiration_date", cert.ExpirationDate.String())
+		}
+		serverCertificates = append(serverCertificates, object)
 	}
 	return serverCertificates, nil
 }
 
-func (provider *OutscaleOAPI) deleteServerCertificates(ctx context.Context, serverCertificates []Object) {
+func (provider *OutscaleOAPI) deleteServerCertificates(ctx context.Context, serverCertificates []Object) []DeleteResult {
 	if len(serverCertificates) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(serverCertificates))
 	for _, cert := range serverCertificates {
-		log.Printf("Deleting server certificate %s... ", cert)
-		deleteOpts := osc.DeleteServerCertificateRequest{Name: cert}
+		ContextLogger(ctx).Printf("Deleting server certificate %s... ", cert)
+		deleteOpts := osc.DeleteServerCertificateRequest{Name: cert.Id}
 		_, err := provider.client.DeleteServerCertificate(ctx, deleteOpts)
 		if err != nil {
-			log.Printf("Error while deleting server certificate: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting server certificate: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(cert, err))
 	}
+	return results
 }
 
 func (provider *OutscaleOAPI) readDhcpOptions(ctx context.Context) ([]Object, error) {
@@ -1629,23 +2093,34 @@ func (provider *OutscaleOAPI) readDhcpOptions(ctx context.Context) ([]Object, er
 		return nil, fmt.Errorf("read dhcp options: %w", getErrorInfo(err))
 	}
 	for _, option := range *read.DhcpOptionsSets {
-		dhcpOptions = append(dhcpOptions, *option.DhcpOptionsSetId)
+		var tags []osc.ResourceTag
+		if option.Tags != nil {
+			tags = *option.Tags
+		}
+		object := provider.newObject(*option.DhcpOptionsSetId, tags)
+		if option.DomainName != nil {
+			object.SetAttribute("domain_name", *option.DomainName)
+		}
+		dhcpOptions = append(dhcpOptions, object)
 	}
 	return dhcpOptions, nil
 }
 
-func (provider *OutscaleOAPI) deleteDhcpOptions(ctx context.Context, dhcpOptions []Object) {
+func (provider *OutscaleOAPI) deleteDhcpOptions(ctx context.Context, dhcpOptions []Object) []DeleteResult {
 	if len(dhcpOptions) == 0 {
-		return
+		return nil
 	}
+	results := make([]DeleteResult, 0, len(dhcpOptions))
 	for _, option := range dhcpOptions {
-		log.Printf("Deleting DHCP option %s... ", option)
-		deleteOpts := osc.DeleteDhcpOptionsRequest{DhcpOptionsSetId: option}
+		ContextLogger(ctx).Printf("Deleting DHCP option %s... ", option)
+		deleteOpts := osc.DeleteDhcpOptionsRequest{DhcpOptionsSetId: option.Id}
 		_, err := provider.client.DeleteDhcpOptions(ctx, deleteOpts)
 		if err != nil {
-			log.Printf("Error while deleting DHCP option: %v\n", getErrorInfo(err))
+			ContextLogger(ctx).Printf("Error while deleting DHCP option: %v\n", getErrorInfo(err))
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(option, err))
 	}
+	return results
 }
@@ -3,7 +3,10 @@ package outscale_oapi
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 
+	. "github.com/outscale/frieza/internal/common"
 	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
 )
 
@@ -26,3 +29,83 @@ func getErrorInfo(err error) error {
 
 	return err
 }
+
+func tagsToMap(tags []osc.ResourceTag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		out[tag.Key] = tag.Value
+	}
+	return out
+}
+
+func (provider *OutscaleOAPI) newObject(id string, tags []osc.ResourceTag) Object {
+	object := NewObject(id)
+	object.Tags = tagsToMap(tags)
+	object.Name = object.Tags["Name"]
+	object.Region = provider.region
+	return object
+}
+
+func deleteErrorKind(err error) DeleteErrorKind {
+	var protected *ProtectedError
+	switch {
+	case errors.As(err, &protected):
+		return DeleteErrorForbidden
+	case osc.IsNotFound(err):
+		return DeleteErrorNotFound
+	case osc.IsConflict(err):
+		return DeleteErrorDependency
+	case osc.IsAuthError(err):
+		return DeleteErrorForbidden
+	case isThrottled(err):
+		return DeleteErrorThrottled
+	}
+	return DeleteErrorRetryable
+}
+
+// throttlingErrors are the error types of calls rejected by the API rate
+// limit.
+var throttlingErrors = []string{"RequestLimitExceeded", "TooManyRequests", "Throttling"}
+
+func isThrottled(err error) bool {
+	if apiError := osc.AsErrorResponse(err); apiError != nil {
+		for _, item := range apiError.Errors {
+			if slices.Contains(throttlingErrors, item.Type) || slices.Contains(throttlingErrors, item.Code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newDeleteResult(object Object, err error) DeleteResult {
+	if err == nil {
+		return DeleteSucceeded(object)
+	}
+	return DeleteFailed(object, deleteErrorKind(err), err)
+}
+
+// cacheMap is a map of API resources by id, safe for concurrent use.
+type cacheMap[T any] struct {
+	mutex sync.RWMutex
+	items map[string]*T
+}
+
+func newCacheMap[T any]() *cacheMap[T] {
+	return &cacheMap[T]{items: make(map[string]*T)}
+}
+
+func (cache *cacheMap[T]) get(id string) *T {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return cache.items[id]
+}
+
+func (cache *cacheMap[T]) set(id string, item *T) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.items[id] = item
+}
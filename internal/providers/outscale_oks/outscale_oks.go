@@ -2,13 +2,12 @@ package outscale_oks
 
 import (
 	"context"
-	"log"
 
 	. "github.com/outscale/frieza/internal/common"
+	"github.com/outscale/osc-sdk-go/v3/pkg/middleware"
 	"github.com/outscale/osc-sdk-go/v3/pkg/oks"
 	"github.com/outscale/osc-sdk-go/v3/pkg/options"
 	"github.com/outscale/osc-sdk-go/v3/pkg/profile"
-	"github.com/teris-io/cli"
 )
 
 const (
@@ -18,12 +17,16 @@ const (
 	typeCluster = "cluster"
 )
 
+// defaultRateLimit is the rate limit of the SDK.
+const defaultRateLimit = 5
+
 type OutscaleOKS struct {
 	client *oks.Client
+	region string
 }
 
-func (provider *OutscaleOKS) StringObject(object string, typeName string) string {
-	return object
+func (provider *OutscaleOKS) StringObject(object Object, typeName string) string {
+	return object.String()
 }
 
 func New(config ProviderConfig, debug bool) (*OutscaleOKS, error) {
@@ -46,13 +49,22 @@ func New(config ProviderConfig, debug bool) (*OutscaleOKS, error) {
 		profile.Region = region
 	}
 
-	client, err := oks.NewClient(profile, options.WithUseragent("frieza/"+FullVersion()))
+	rate, err := ParseRateLimit(config, defaultRateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := oks.NewClient(profile,
+		options.WithUseragent("frieza/"+FullVersion()),
+		middleware.WithMiddleware(middleware.MiddlewareSlotRateLimit, NewRateLimiter(rate)),
+	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &OutscaleOKS{
 		client: client,
+		region: profile.Region,
 	}, nil
 }
 
@@ -63,11 +75,25 @@ func Types() []ObjectType {
 	}
 }
 
-func Cli() (string, cli.Command) {
-	return Name, cli.NewCommand(Name, "create new Outscale OKS profile").
-		WithOption(cli.NewOption("region", "Outscale region (e.g. eu-west-2)")).
-		WithOption(cli.NewOption("ak", "access key")).
-		WithOption(cli.NewOption("sk", "secret key"))
+func Dependencies() map[ObjectType][]ObjectType {
+	return map[ObjectType][]ObjectType{
+		typeProject: {typeCluster},
+	}
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:        Name,
+		Description: "Outscale OKS",
+		New:         ProviderConstructor(New),
+		Types:       Types,
+		Config: []ProviderConfigOption{
+			{Name: "region", Description: "Outscale region (e.g. eu-west-2)"},
+			{Name: "ak", Description: "access key"},
+			{Name: "sk", Description: "secret key"},
+			RateLimitOption(defaultRateLimit),
+		},
+	})
 }
 
 func (provider *OutscaleOKS) Name() string {
@@ -78,6 +104,20 @@ func (provider *OutscaleOKS) Types() []ObjectType {
 	return Types()
 }
 
+func (provider *OutscaleOKS) Dependencies() map[ObjectType][]ObjectType {
+	return Dependencies()
+}
+
+// TaggedTypes: clusters and projects both carry tags.
+func (provider *OutscaleOKS) TaggedTypes() []ObjectType {
+	return Types()
+}
+
+// MaxConcurrency keeps simultaneous reads under the API rate limits.
+func (provider *OutscaleOKS) MaxConcurrency() int {
+	return 2
+}
+
 func (provider *OutscaleOKS) AuthTest(ctx context.Context) error {
 	// TODO
 	return nil
@@ -105,7 +145,17 @@ func (provider *OutscaleOKS) readCluster(ctx context.Context) ([]Object, error)
 		if cluster.Statuses.Status != nil && *cluster.Statuses.Status == "failed" {
 			continue
 		}
-		clusters = append(clusters, cluster.Id)
+		object := NewObject(cluster.Id)
+		object.Name = cluster.Name
+		object.Tags = cluster.Tags
+		object.Region = provider.region
+		object.SetCreatedAt(cluster.Statuses.CreatedAt)
+		object.SetAttribute("project_id", cluster.ProjectId)
+		object.SetAttribute("version", cluster.Version)
+		if cluster.Statuses.Status != nil {
+			object.SetAttribute("status", string(*cluster.Statuses.Status))
+		}
+		clusters = append(clusters, object)
 	}
 
 	return clusters, nil
@@ -122,51 +172,105 @@ func (provider *OutscaleOKS) readProject(ctx context.Context) ([]Object, error)
 		if project.Status == oks.ProjectStatusFailed {
 			continue
 		}
-		projectcs = append(projectcs, project.Id)
+		object := NewObject(project.Id)
+		object.Name = project.Name
+		object.Tags = project.Tags
+		object.Region = project.Region
+		object.SetCreatedAt(project.CreatedAt)
+		object.SetAttribute("cidr", project.Cidr)
+		object.SetAttribute("status", string(project.Status))
+		projectcs = append(projectcs, object)
 	}
 
 	return projectcs, nil
 }
 
-func (provider *OutscaleOKS) DeleteObjects(ctx context.Context, typeName string, objects []Object) {
+func (provider *OutscaleOKS) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
 	switch typeName {
 	case typeProject:
-		provider.deleteProject(ctx, objects)
+		return provider.deleteProject(ctx, objects)
 	case typeCluster:
-		provider.deleteCluster(ctx, objects)
+		return provider.deleteCluster(ctx, objects)
 	}
+	return nil
 }
 
-func (provider *OutscaleOKS) deleteCluster(ctx context.Context, objects []Object) {
+func (provider *OutscaleOKS) deleteCluster(ctx context.Context, objects []Object) []DeleteResult {
 	if len(objects) == 0 {
-		return
+		return nil
 	}
 
-	for _, clusterID := range objects {
-		log.Printf("Deleting cluster %s... ", clusterID)
+	results := make([]DeleteResult, 0, len(objects))
+	for _, cluster := range objects {
+		ContextLogger(ctx).Printf("Deleting cluster %s... ", cluster)
 
-		_, err := provider.client.DeleteCluster(ctx, clusterID)
+		_, err := provider.client.DeleteCluster(ctx, cluster.Id)
 		if err != nil {
-			log.Printf("Error while deleting cluster: %v\n", err)
+			ContextLogger(ctx).Printf("Error while deleting cluster: %v\n", err)
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(cluster, err))
 	}
+	return results
 }
 
-func (provider *OutscaleOKS) deleteProject(ctx context.Context, objects []Object) {
+func (provider *OutscaleOKS) deleteProject(ctx context.Context, objects []Object) []DeleteResult {
 	if len(objects) == 0 {
-		return
+		return nil
 	}
 
-	for _, projectID := range objects {
-		log.Printf("Deleting project %s... ", projectID)
+	results := make([]DeleteResult, 0, len(objects))
+	for _, project := range objects {
+		ContextLogger(ctx).Printf("Deleting project %s... ", project)
 
-		_, err := provider.client.DeleteProject(ctx, projectID)
+		_, err := provider.client.DeleteProject(ctx, project.Id)
 		if err != nil {
-			log.Printf("Error while deleting project: %v\n", err)
+			ContextLogger(ctx).Printf("Error while deleting project: %v\n", err)
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
+		}
+		results = append(results, newDeleteResult(project, err))
+	}
+	return results
+}
+
+func newDeleteResult(object Object, err error) DeleteResult {
+	if err == nil {
+		return DeleteSucceeded(object)
+	}
+	kind := DeleteErrorRetryable
+	switch {
+	case oks.IsNotFound(err):
+		kind = DeleteErrorNotFound
+	case oks.IsConflict(err):
+		kind = DeleteErrorDependency
+	case isForbidden(err):
+		kind = DeleteErrorForbidden
+	case isThrottled(err):
+		kind = DeleteErrorThrottled
+	}
+	return DeleteFailed(object, kind, err)
+}
+
+func isForbidden(err error) bool {
+	if apiError := oks.AsErrorResponse(err); apiError != nil {
+		for _, item := range apiError.Errors {
+			if item.Code == "401" || item.Code == "403" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isThrottled(err error) bool {
+	if apiError := oks.AsErrorResponse(err); apiError != nil {
+		for _, item := range apiError.Errors {
+			if item.Code == "429" || item.Code == "503" {
+				return true
+			}
 		}
 	}
+	return false
 }
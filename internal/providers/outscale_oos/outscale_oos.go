@@ -5,18 +5,21 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"log"
+	"iter"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/logging"
 	. "github.com/outscale/frieza/internal/common"
 	"github.com/outscale/osc-sdk-go/v3/pkg/oos"
 	"github.com/outscale/osc-sdk-go/v3/pkg/profile"
-	"github.com/teris-io/cli"
 )
 
 const (
@@ -27,9 +30,55 @@ const (
 
 type OutscaleOOS struct {
 	client *oos.Client
+	// tagging reads bucket tags, which the OOS client does not expose.
+	tagging *s3.Client
+	region  string
 }
 
 func New(config ProviderConfig, debug bool) (*OutscaleOOS, error) {
+	profile, err := loadProfile(config)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := clientOptions(config, debug)
+	if err != nil {
+		return nil, err
+	}
+	// Note: Creating client still needs a context, but this is during initialization
+	// In a future refactor, we could pass context to New() as well
+	client, err := oos.NewClient(context.Background(), profile, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tagging, err := newS3Client(context.Background(), profile, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutscaleOOS{
+		client:  client,
+		tagging: tagging,
+		region:  profile.Region,
+	}, nil
+}
+
+// NewS3Client builds a S3 client on the OOS endpoint of a provider
+// configuration, to store data like snapshots.
+func NewS3Client(ctx context.Context, config ProviderConfig, debug bool) (*s3.Client, error) {
+	profile, err := loadProfile(config)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := clientOptions(config, debug)
+	if err != nil {
+		return nil, err
+	}
+	return newS3Client(ctx, profile, opts)
+}
+
+// loadProfile reads the Outscale profile, overridden by the provider
+// configuration.
+func loadProfile(config ProviderConfig) (*profile.Profile, error) {
 	profileName := config["profile"]
 	profilePath := config["path"]
 	profile, err := profile.NewFrom(profileName, profilePath)
@@ -48,9 +97,19 @@ func New(config ProviderConfig, debug bool) (*OutscaleOOS, error) {
 	if region, ok := config["region"]; ok {
 		profile.Region = region
 	}
+	return profile, nil
+}
 
+func clientOptions(config ProviderConfig, debug bool) ([]aws_config.LoadOptionsFunc, error) {
+	rate, err := ParseRateLimit(config, 0)
+	if err != nil {
+		return nil, err
+	}
 	ua := "frieza/" + FullVersion()
 	opts := []aws_config.LoadOptionsFunc{aws_config.WithAppID(ua)}
+	if rate > 0 {
+		opts = append(opts, aws_config.WithHTTPClient(NewRateLimitedClient(rate)))
+	}
 	if debug {
 		opts = append(opts,
 			aws_config.WithClientLogMode(aws.LogRequest|aws.LogRequestWithBody|aws.LogResponseWithBody),
@@ -58,16 +117,33 @@ func New(config ProviderConfig, debug bool) (*OutscaleOOS, error) {
 			oos.WithUseragent(ua),
 		)
 	}
-	// Note: Creating client still needs a context, but this is during initialization
-	// In a future refactor, we could pass context to New() as well
-	client, err := oos.NewClient(context.Background(), profile, opts...)
+	return opts, nil
+}
+
+// newS3Client builds a S3 client configured like oos.NewClient, which does
+// not expose all S3 calls.
+func newS3Client(ctx context.Context, p *profile.Profile, opts []aws_config.LoadOptionsFunc) (*s3.Client, error) {
+	endpoint, err := p.GetEndpoint(profile.OscServiceOOS)
 	if err != nil {
 		return nil, err
 	}
-
-	return &OutscaleOOS{
-		client: client,
-	}, nil
+	loadOpts := []func(*aws_config.LoadOptions) error{
+		aws_config.WithRegion(p.Region),
+		aws_config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(p.AccessKey, p.SecretKey, ""),
+		),
+	}
+	for _, opt := range opts {
+		loadOpts = append(loadOpts, opt)
+	}
+	cfg, err := aws_config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = &endpoint
+		o.UsePathStyle = true
+	}), nil
 }
 
 func Types() []ObjectType {
@@ -78,11 +154,31 @@ func Types() []ObjectType {
 	return object_types
 }
 
-func Cli() (string, cli.Command) {
-	return Name, cli.NewCommand(Name, "create new Outscale OOS profile").
-		WithOption(cli.NewOption("region", "Outscale region (e.g. eu-west-2)")).
-		WithOption(cli.NewOption("ak", "access key")).
-		WithOption(cli.NewOption("sk", "secret key"))
+func Dependencies() map[ObjectType][]ObjectType {
+	return map[ObjectType][]ObjectType{
+		typeBucket: {typeBucketObject},
+	}
+}
+
+// TaggedTypes lists types selectable by tags: bucket tags also apply to their
+// objects.
+func TaggedTypes() []ObjectType {
+	return []ObjectType{typeBucketObject, typeBucket}
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:        Name,
+		Description: "Outscale OOS",
+		New:         ProviderConstructor(New),
+		Types:       Types,
+		Config: []ProviderConfigOption{
+			{Name: "region", Description: "Outscale region (e.g. eu-west-2)"},
+			{Name: "ak", Description: "access key"},
+			{Name: "sk", Description: "secret key"},
+			RateLimitOption(0),
+		},
+	})
 }
 
 func (provider *OutscaleOOS) Name() string {
@@ -93,6 +189,14 @@ func (provider *OutscaleOOS) Types() []ObjectType {
 	return Types()
 }
 
+func (provider *OutscaleOOS) Dependencies() map[ObjectType][]ObjectType {
+	return Dependencies()
+}
+
+func (provider *OutscaleOOS) TaggedTypes() []ObjectType {
+	return TaggedTypes()
+}
+
 func (provider *OutscaleOOS) AuthTest(ctx context.Context) error {
 	_, err := provider.client.ListBuckets(ctx, &s3.ListBucketsInput{})
 	if err != nil {
@@ -111,23 +215,69 @@ func (provider *OutscaleOOS) ReadObjects(ctx context.Context, typeName string) (
 	return []Object{}, nil
 }
 
-func (provider *OutscaleOOS) DeleteObjects(ctx context.Context, typeName string, objects []Object) {
+func (provider *OutscaleOOS) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
 	switch typeName {
 	case typeBucketObject:
-		provider.deleteBucketObjects(ctx, objects)
+		return provider.deleteBucketObjects(ctx, objects)
 	case typeBucket:
-		provider.deleteBuckets(ctx, objects)
+		return provider.deleteBuckets(ctx, objects)
+	}
+	return nil
+}
+
+// ExistingObjects checks objects with HEAD requests instead of listing every
+// bucket.
+func (provider *OutscaleOOS) ExistingObjects(ctx context.Context, typeName string, objects []Object) ([]Object, error) {
+	existing := make([]Object, 0, len(objects))
+	for _, object := range objects {
+		var err error
+		switch typeName {
+		case typeBucketObject:
+			bucketName, key, decodeErr := decodeBucketobject(&object.Id)
+			if decodeErr != nil {
+				// It cannot have been deleted, keep it.
+				existing = append(existing, object)
+				continue
+			}
+			_, err = provider.client.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket: &bucketName,
+				Key:    &key,
+			})
+		case typeBucket:
+			bucketName, decodeErr := decodeBucket(&object.Id)
+			if decodeErr != nil {
+				// It cannot have been deleted, keep it.
+				existing = append(existing, object)
+				continue
+			}
+			_, err = provider.client.HeadBucket(ctx, &s3.HeadBucketInput{
+				Bucket: &bucketName,
+			})
+		default:
+			return nil, errors.ErrUnsupported
+		}
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("head %s: %w", provider.StringObject(object, typeName), err)
+		}
+		existing = append(existing, object)
 	}
+	return existing, nil
 }
 
-func (provider *OutscaleOOS) StringObject(object string, typeName string) string {
+func (provider *OutscaleOOS) StringObject(object Object, typeName string) string {
+	if len(object.Name) > 0 {
+		return object.Name
+	}
 	switch typeName {
 	case typeBucketObject:
-		if bucketName, key, err := decodeBucketobject(&object); err == nil {
+		if bucketName, key, err := decodeBucketobject(&object.Id); err == nil {
 			return bucketName + ":" + key
 		}
 	case typeBucket:
-		if bucketName, err := decodeBucket(&object); err == nil {
+		if bucketName, err := decodeBucket(&object.Id); err == nil {
 			return bucketName
 		}
 	}
@@ -160,46 +310,105 @@ func decodeBucketobject(encodedObject *string) (string, string, error) {
 	return bucketName, string(binkey), nil
 }
 
-func (provider *OutscaleOOS) readBucketObjects(ctx context.Context) ([]Object, error) {
-	var objects []Object
-	result, err := provider.client.ListBuckets(ctx, &s3.ListBucketsInput{})
-	if err != nil {
-		return nil, err
+// StreamObjects lists bucket objects page by page, buckets can hold millions
+// of them.
+func (provider *OutscaleOOS) StreamObjects(ctx context.Context, typeName string) iter.Seq2[Object, error] {
+	if typeName != typeBucketObject {
+		return ObjectsSeq(provider.ReadObjects(ctx, typeName))
 	}
-	for _, bucket := range result.Buckets {
-		result, err := provider.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-			Bucket: bucket.Name,
-		})
+	return provider.streamBucketObjects(ctx)
+}
+
+func (provider *OutscaleOOS) readBucketObjects(ctx context.Context) ([]Object, error) {
+	return CollectObjects(provider.streamBucketObjects(ctx))
+}
+
+func (provider *OutscaleOOS) streamBucketObjects(ctx context.Context) iter.Seq2[Object, error] {
+	return func(yield func(Object, error) bool) {
+		result, err := provider.client.ListBuckets(ctx, &s3.ListBucketsInput{})
 		if err != nil {
-			continue
+			yield(Object{}, err)
+			return
 		}
-		for _, object := range result.Contents {
-			objects = append(objects, encodeBucketObject(bucket.Name, object.Key))
+		for _, bucket := range result.Buckets {
+			var bucketTags map[string]string
+			tagsRead := false
+			paginator := s3.NewListObjectsV2Paginator(provider.client, &s3.ListObjectsV2Input{
+				Bucket: bucket.Name,
+			})
+			for firstPage := true; paginator.HasMorePages(); firstPage = false {
+				page, err := paginator.NextPage(ctx)
+				if firstPage && isUnlistable(err) {
+					break
+				}
+				// A partial listing would make clean delete the objects of
+				// the missing pages.
+				if err != nil {
+					yield(Object{}, fmt.Errorf("list objects of bucket %s: %w", *bucket.Name, err))
+					return
+				}
+				if !tagsRead && len(page.Contents) > 0 {
+					bucketTags, err = provider.readBucketTags(ctx, bucket.Name)
+					if err != nil {
+						yield(Object{}, err)
+						return
+					}
+					tagsRead = true
+				}
+				for _, object := range page.Contents {
+					if !yield(provider.newBucketObject(bucket.Name, object, bucketTags), nil) {
+						return
+					}
+				}
+			}
 		}
 	}
-	return objects, nil
 }
 
-func (provider *OutscaleOOS) deleteBucketObjects(ctx context.Context, bucketObjects []Object) {
-	for _, encodedBucketObject := range bucketObjects {
-		log.Printf(
+func (provider *OutscaleOOS) newBucketObject(bucketName *string, object types.Object, bucketTags map[string]string) Object {
+	bucketObject := NewObject(encodeBucketObject(bucketName, object.Key))
+	bucketObject.Name = *bucketName + ":" + *object.Key
+	bucketObject.Tags = bucketTags
+	bucketObject.Region = provider.region
+	if object.LastModified != nil {
+		bucketObject.SetCreatedAt(*object.LastModified)
+	}
+	bucketObject.SetAttribute("bucket", *bucketName)
+	bucketObject.SetAttribute("key", *object.Key)
+	if object.Size != nil {
+		bucketObject.SetAttribute("size", strconv.FormatInt(*object.Size, 10))
+	}
+	if object.ETag != nil {
+		bucketObject.SetAttribute("etag", strings.Trim(*object.ETag, "\""))
+	}
+	return bucketObject
+}
+
+func (provider *OutscaleOOS) deleteBucketObjects(ctx context.Context, bucketObjects []Object) []DeleteResult {
+	results := make([]DeleteResult, 0, len(bucketObjects))
+	for _, bucketObject := range bucketObjects {
+		ContextLogger(ctx).Printf(
 			"Deleting object: %s ... ",
-			provider.StringObject(encodedBucketObject, typeBucketObject),
+			provider.StringObject(bucketObject, typeBucketObject),
 		)
-		bucketName, key, err := decodeBucketobject(&encodedBucketObject)
+		bucketName, key, err := decodeBucketobject(&bucketObject.Id)
 		if err != nil {
-			log.Println("Error while reading object details: ", err.Error())
+			ContextLogger(ctx).Println("Error while reading object details: ", err.Error())
+			results = append(results, DeleteFailed(bucketObject, DeleteErrorForbidden, err))
+			continue
 		}
 		_, err = provider.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 			Bucket: &bucketName,
 			Key:    &key,
 		})
 		if err != nil {
-			log.Println("Error while deleting object: ", err.Error())
+			ContextLogger(ctx).Println("Error while deleting object: ", err.Error())
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(bucketObject, err))
 	}
+	return results
 }
 
 func encodeBucket(bucketName *string) string {
@@ -222,25 +431,109 @@ func (provider *OutscaleOOS) readBuckets(ctx context.Context) ([]Object, error)
 		return nil, fmt.Errorf("read buckets: %w", err)
 	}
 	for _, b := range result.Buckets {
-		buckets = append(buckets, encodeBucket(b.Name))
+		bucket := NewObject(encodeBucket(b.Name))
+		bucket.Name = *b.Name
+		bucket.Tags, err = provider.readBucketTags(ctx, b.Name)
+		if err != nil {
+			return nil, err
+		}
+		bucket.Region = provider.region
+		if b.CreationDate != nil {
+			bucket.SetCreatedAt(*b.CreationDate)
+		}
+		buckets = append(buckets, bucket)
 	}
 	return buckets, nil
 }
 
-func (provider *OutscaleOOS) deleteBuckets(ctx context.Context, buckets []Object) {
-	for _, b64Bucket := range buckets {
-		bucketName, err := decodeBucket(&b64Bucket)
+// readBucketTags returns nil when the bucket has no tags or they are not
+// wanted. Other errors are returned: ignoring them would let objects escape
+// tag filters.
+func (provider *OutscaleOOS) readBucketTags(ctx context.Context, bucketName *string) (map[string]string, error) {
+	if !TagsWanted(ctx) {
+		return nil, nil
+	}
+	result, err := provider.tagging.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+		Bucket: bucketName,
+	})
+	if apiErrorCode(err) == "NoSuchTagSet" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read tags of bucket %s: %w", aws.ToString(bucketName), err)
+	}
+	if len(result.TagSet) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(result.TagSet))
+	for _, tag := range result.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func (provider *OutscaleOOS) deleteBuckets(ctx context.Context, buckets []Object) []DeleteResult {
+	results := make([]DeleteResult, 0, len(buckets))
+	for _, bucket := range buckets {
+		bucketName, err := decodeBucket(&bucket.Id)
 		if err != nil {
+			results = append(results, DeleteFailed(bucket, DeleteErrorForbidden, err))
 			continue
 		}
-		log.Printf("Deleting bucket: %s ... ", bucketName)
+		ContextLogger(ctx).Printf("Deleting bucket: %s ... ", bucketName)
 		_, err = provider.client.DeleteBucket(ctx, &s3.DeleteBucketInput{
 			Bucket: &bucketName,
 		})
 		if err != nil {
-			log.Println("Error while deleting bucket: ", err.Error())
+			ContextLogger(ctx).Println("Error while deleting bucket: ", err.Error())
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(bucket, err))
+	}
+	return results
+}
+
+func newDeleteResult(object Object, err error) DeleteResult {
+	if err == nil {
+		return DeleteSucceeded(object)
 	}
+	kind := DeleteErrorRetryable
+	switch apiErrorCode(err) {
+	case "NoSuchBucket", "NoSuchKey", "NotFound":
+		kind = DeleteErrorNotFound
+	case "BucketNotEmpty":
+		kind = DeleteErrorDependency
+	case "AccessDenied", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		kind = DeleteErrorForbidden
+	case "SlowDown", "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException":
+		kind = DeleteErrorThrottled
+	}
+	return DeleteFailed(object, kind, err)
+}
+
+func isNotFound(err error) bool {
+	switch apiErrorCode(err) {
+	case "NoSuchBucket", "NoSuchKey", "NotFound":
+		return true
+	}
+	return false
+}
+
+// isUnlistable reports whether the objects of a bucket cannot be listed at
+// all, such buckets being skipped.
+func isUnlistable(err error) bool {
+	switch apiErrorCode(err) {
+	case "AccessDenied", "NoSuchBucket":
+		return true
+	}
+	return false
+}
+
+func apiErrorCode(err error) string {
+	var apiError smithy.APIError
+	if errors.As(err, &apiError) {
+		return apiError.ErrorCode()
+	}
+	return ""
 }
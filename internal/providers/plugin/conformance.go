@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+// conformanceMissingId is the identifier of an object which must not exist.
+const conformanceMissingId = "frieza-conformance-missing-object"
+
+// Conformance checks that a plugin follows the protocol. When destructive is
+// set, all objects of the plugin are deleted and must be gone afterwards.
+// logf reports each step.
+func Conformance(ctx context.Context, provider *Plugin, destructive bool, logf func(format string, v ...any)) error {
+	var asProvider Provider = provider
+	types := provider.Types()
+	if len(types) == 0 {
+		return errors.New("types: no type declared")
+	}
+	for i, typeName := range types {
+		if slices.Contains(types[:i], typeName) {
+			return fmt.Errorf("types: %s declared twice", typeName)
+		}
+	}
+	for _, typeName := range provider.TaggedTypes() {
+		if !slices.Contains(types, typeName) {
+			return fmt.Errorf("types: unknown tagged type %s", typeName)
+		}
+	}
+	graph, err := NewDependencyGraph(&asProvider)
+	if err != nil {
+		return fmt.Errorf("types: %w", err)
+	}
+	logf("types: %d types declared\n", len(types))
+
+	if err := provider.AuthTest(ctx); err != nil {
+		return fmt.Errorf("auth_test: %w", err)
+	}
+	logf("auth_test: passed\n")
+
+	objects := make(Objects)
+	for _, typeName := range types {
+		typeObjects, err := provider.ReadObjects(ctx, typeName)
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		ids := make(map[string]bool)
+		for _, object := range typeObjects {
+			if len(object.Id) == 0 {
+				return fmt.Errorf("read: %s object without id", typeName)
+			}
+			if ids[object.Id] {
+				return fmt.Errorf("read: %s object %s returned twice", typeName, object.Id)
+			}
+			ids[object.Id] = true
+			if len(provider.StringObject(object, typeName)) == 0 {
+				return fmt.Errorf("string_object: empty string for %s object %s", typeName, object.Id)
+			}
+		}
+		objects[typeName] = typeObjects
+		logf("read: %d %s objects\n", len(typeObjects), typeName)
+	}
+
+	for _, typeName := range types {
+		missing := NewObject(conformanceMissingId)
+		results := provider.DeleteObjects(ctx, typeName, []Object{missing})
+		if len(results) != 1 || !results[0].Deleted() {
+			return fmt.Errorf("delete: deleting a missing %s object must succeed or report %s", typeName, DeleteErrorNotFound)
+		}
+	}
+	logf("delete: missing objects reported as deleted\n")
+	if !destructive {
+		return nil
+	}
+
+	for {
+		ready := graph.Ready(objects)
+		if len(ready) == 0 {
+			break
+		}
+		for _, typeName := range ready {
+			for _, result := range provider.DeleteObjects(ctx, typeName, objects[typeName]) {
+				if !result.Deleted() {
+					return fmt.Errorf("delete: %s object %s: %w", typeName, result.Object.Id, result.Err)
+				}
+			}
+			logf("delete: %d %s objects deleted\n", len(objects[typeName]), typeName)
+			delete(objects, typeName)
+		}
+	}
+	for _, typeName := range types {
+		remaining, err := provider.ReadObjects(ctx, typeName)
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		if len(remaining) > 0 {
+			return fmt.Errorf("read: %d %s objects remaining after deletion", len(remaining), typeName)
+		}
+	}
+	logf("read: no object remaining\n")
+	return nil
+}
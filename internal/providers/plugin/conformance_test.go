@@ -0,0 +1,31 @@
+package plugin
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+// TestExamplePluginConformance builds the reference plugin and checks it
+// against the protocol, deleting its in-memory objects.
+func TestExamplePluginConformance(t *testing.T) {
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found, cannot build the example plugin")
+	}
+	path := filepath.Join(t.TempDir(), "frieza-plugin-example")
+	build := exec.Command(goCommand, "build", "-o", path, "github.com/outscale/frieza/cmd/frieza-plugin-example")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("cannot build example plugin: %s\n%s", err, output)
+	}
+	provider, err := New(ProviderConfig{"path": path}, false)
+	if err != nil {
+		t.Fatalf("cannot start example plugin: %s", err)
+	}
+	if err := Conformance(context.Background(), provider, true, t.Logf); err != nil {
+		t.Fatalf("example plugin conformance failed: %s", err)
+	}
+}
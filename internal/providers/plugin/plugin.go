@@ -0,0 +1,283 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+const Name = "plugin"
+
+// startTimeout bounds the init and types calls made when the provider is
+// created.
+const startTimeout = 30 * time.Second
+
+// Plugin forwards Provider calls to an external executable speaking the
+// protocol described in docs/plugins.md.
+type Plugin struct {
+	client       *client
+	types        []ObjectType
+	dependencies map[ObjectType][]ObjectType
+	taggedTypes  []ObjectType
+}
+
+func checkConfig(config ProviderConfig) error {
+	if len(config["path"]) == 0 {
+		return errors.New("path is needed")
+	}
+	return nil
+}
+
+func New(config ProviderConfig, debug bool) (*Plugin, error) {
+	if err := checkConfig(config); err != nil {
+		return nil, err
+	}
+	client, err := startClient(config["path"], strings.Fields(config["args"]), debug)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+
+	var initReply InitReply
+	params := InitParams{ProtocolVersion: ProtocolVersion, Config: config}
+	if err := client.call(ctx, MethodInit, params, &initReply); err != nil {
+		return nil, client.stop(fmt.Errorf("cannot initialize plugin %s: %w", config["path"], err))
+	}
+	if initReply.ProtocolVersion != ProtocolVersion {
+		return nil, client.stop(fmt.Errorf(
+			"plugin %s speaks protocol version %d, frieza supports version %d",
+			config["path"],
+			initReply.ProtocolVersion,
+			ProtocolVersion,
+		))
+	}
+	var typesReply TypesReply
+	if err := client.call(ctx, MethodTypes, nil, &typesReply); err != nil {
+		return nil, client.stop(fmt.Errorf("cannot get types of plugin %s: %w", config["path"], err))
+	}
+	return &Plugin{
+		client:       client,
+		types:        typesReply.Types,
+		dependencies: typesReply.Dependencies,
+		taggedTypes:  typesReply.TaggedTypes,
+	}, nil
+}
+
+// Types is unknown until a plugin executable is started.
+func Types() []ObjectType {
+	return nil
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:        Name,
+		Description: "external plugin",
+		New:         ProviderConstructor(New),
+		Types:       Types,
+		Config: []ProviderConfigOption{
+			{Name: "path", Description: "plugin executable"},
+			{Name: "args", Description: "plugin arguments (separated by spaces)"},
+		},
+	})
+}
+
+func (provider *Plugin) Name() string {
+	return Name
+}
+
+func (provider *Plugin) Types() []ObjectType {
+	return provider.types
+}
+
+func (provider *Plugin) Dependencies() map[ObjectType][]ObjectType {
+	return provider.dependencies
+}
+
+func (provider *Plugin) TaggedTypes() []ObjectType {
+	return provider.taggedTypes
+}
+
+// MaxConcurrency is 1 as a plugin answers one request at a time.
+func (provider *Plugin) MaxConcurrency() int {
+	return 1
+}
+
+func (provider *Plugin) AuthTest(ctx context.Context) error {
+	return provider.client.call(ctx, MethodAuthTest, nil, nil)
+}
+
+func (provider *Plugin) ReadObjects(ctx context.Context, typeName string) ([]Object, error) {
+	var reply ReadReply
+	if err := provider.client.call(ctx, MethodRead, ReadParams{Type: typeName}, &reply); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", typeName, err)
+	}
+	return reply.Objects, nil
+}
+
+func (provider *Plugin) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
+	if len(objects) == 0 {
+		return nil
+	}
+	var reply DeleteReply
+	params := DeleteParams{Type: typeName, Objects: objects}
+	if err := provider.client.call(ctx, MethodDelete, params, &reply); err != nil {
+		ContextLogger(ctx).Printf("Cannot delete %s: %s\n", typeName, err.Error())
+		results := make([]DeleteResult, 0, len(objects))
+		for _, object := range objects {
+			results = append(results, DeleteFailed(object, DeleteErrorRetryable, err))
+		}
+		return results
+	}
+	replied := make(map[string]DeleteObjectResult, len(reply.Results))
+	for _, result := range reply.Results {
+		replied[result.Id] = result
+	}
+	results := make([]DeleteResult, 0, len(objects))
+	for _, object := range objects {
+		result, found := replied[object.Id]
+		switch {
+		case !found:
+			results = append(results, DeleteFailed(object, DeleteErrorRetryable, errors.New("no result from plugin")))
+		case len(result.Error) == 0 && len(result.Kind) == 0:
+			results = append(results, DeleteSucceeded(object))
+		default:
+			results = append(results, DeleteFailed(object, deleteErrorKind(result.Kind), errors.New(result.Error)))
+		}
+	}
+	return results
+}
+
+func deleteErrorKind(kind DeleteErrorKind) DeleteErrorKind {
+	switch kind {
+	case DeleteErrorDependency, DeleteErrorForbidden, DeleteErrorNotFound, DeleteErrorThrottled:
+		return kind
+	default:
+		return DeleteErrorRetryable
+	}
+}
+
+func (provider *Plugin) StringObject(object Object, typeName string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	var reply StringObjectReply
+	params := StringObjectParams{Type: typeName, Object: object}
+	if err := provider.client.call(ctx, MethodStringObject, params, &reply); err != nil || len(reply.String) == 0 {
+		return object.String()
+	}
+	return reply.String
+}
+
+// client sends one request at a time to the plugin process. The process
+// stops when frieza closes its standard input.
+type client struct {
+	mutex   sync.Mutex
+	cmd     *exec.Cmd
+	input   io.WriteCloser
+	output  *json.Decoder
+	debug   bool
+	lastId  int
+	stopped error
+}
+
+func startClient(path string, args []string, debug bool) (*client, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = os.Stderr
+	input, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start plugin %s: %w", path, err)
+	}
+	return &client{
+		cmd:    cmd,
+		input:  input,
+		output: json.NewDecoder(output),
+		debug:  debug,
+	}, nil
+}
+
+// call sends a request and decodes the result in reply, which can be nil.
+// The process is killed if ctx ends before the response.
+func (client *client) call(ctx context.Context, method string, params any, reply any) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	if client.stopped != nil {
+		return client.stopped
+	}
+	client.lastId++
+	request := Request{Id: client.lastId, Method: method}
+	if params != nil {
+		var err error
+		if request.Params, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+	requestJson, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	if client.debug {
+		ContextLogger(ctx).Printf("plugin request: %s\n", requestJson)
+	}
+	if _, err := client.input.Write(append(requestJson, '\n')); err != nil {
+		return client.stop(fmt.Errorf("plugin stopped: %w", err))
+	}
+
+	responses := make(chan error, 1)
+	var response Response
+	go func() {
+		responses <- client.output.Decode(&response)
+	}()
+	select {
+	case <-ctx.Done():
+		err := client.stop(fmt.Errorf("plugin killed: %w", ctx.Err()))
+		<-responses
+		return err
+	case err := <-responses:
+		if err != nil {
+			return client.stop(fmt.Errorf("plugin stopped: %w", err))
+		}
+	}
+	if client.debug {
+		ContextLogger(ctx).Printf("plugin response: %s\n", response.Result)
+	}
+	if response.Id != request.Id {
+		return client.stop(fmt.Errorf("plugin answered request %d instead of %d", response.Id, request.Id))
+	}
+	if response.Error != nil {
+		return errors.New(response.Error.Message)
+	}
+	if reply == nil || len(response.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(response.Result, reply); err != nil {
+		return fmt.Errorf("invalid %s result from plugin: %w", method, err)
+	}
+	return nil
+}
+
+// stop kills the process, which cannot be used after err.
+func (client *client) stop(err error) error {
+	if client.stopped != nil {
+		return err
+	}
+	client.stopped = err
+	_ = client.cmd.Process.Kill()
+	_ = client.cmd.Wait()
+	return err
+}
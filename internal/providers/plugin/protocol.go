@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	. "github.com/outscale/frieza/internal/common"
+)
+
+// ProtocolVersion is increased on incompatible protocol changes.
+const ProtocolVersion = 1
+
+// Methods a plugin must answer, see docs/plugins.md.
+const (
+	MethodInit         = "init"
+	MethodTypes        = "types"
+	MethodAuthTest     = "auth_test"
+	MethodRead         = "read"
+	MethodDelete       = "delete"
+	MethodStringObject = "string_object"
+)
+
+// Request is written by frieza on the plugin standard input, one per line.
+type Request struct {
+	Id     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is written by the plugin on its standard output, one per line,
+// with the identifier of the request.
+type Response struct {
+	Id     int             `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *ResponseError  `json:"error,omitempty"`
+}
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type InitParams struct {
+	ProtocolVersion int            `json:"protocol_version"`
+	Config          ProviderConfig `json:"config"`
+}
+
+type InitReply struct {
+	ProtocolVersion int `json:"protocol_version"`
+}
+
+type TypesReply struct {
+	Types        []ObjectType                `json:"types"`
+	Dependencies map[ObjectType][]ObjectType `json:"dependencies,omitempty"`
+	TaggedTypes  []ObjectType                `json:"tagged_types,omitempty"`
+}
+
+type ReadParams struct {
+	Type ObjectType `json:"type"`
+}
+
+type ReadReply struct {
+	Objects []Object `json:"objects"`
+}
+
+type DeleteParams struct {
+	Type    ObjectType `json:"type"`
+	Objects []Object   `json:"objects"`
+}
+
+// DeleteObjectResult is the outcome of the deletion of one object: Error is
+// empty on success, Kind classifies failures (retryable by default).
+type DeleteObjectResult struct {
+	Id    string          `json:"id"`
+	Kind  DeleteErrorKind `json:"kind,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+type DeleteReply struct {
+	Results []DeleteObjectResult `json:"results"`
+}
+
+type StringObjectParams struct {
+	Type   ObjectType `json:"type"`
+	Object Object     `json:"object"`
+}
+
+type StringObjectReply struct {
+	String string `json:"string"`
+}
+
+// Handler answers a request of a plugin, the result is serialized in the
+// response.
+type Handler func(method string, params json.RawMessage) (any, error)
+
+// Serve runs the plugin side of the protocol until input is closed. It helps
+// writing plugins in Go.
+func Serve(input io.Reader, output io.Writer, handler Handler) error {
+	decoder := json.NewDecoder(bufio.NewReader(input))
+	encoder := json.NewEncoder(output)
+	for {
+		var request Request
+		if err := decoder.Decode(&request); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("cannot read request: %w", err)
+		}
+		response := Response{Id: request.Id}
+		result, err := handler(request.Method, request.Params)
+		if err == nil {
+			response.Result, err = json.Marshal(result)
+		}
+		if err != nil {
+			response.Error = &ResponseError{Message: err.Error()}
+		}
+		if err := encoder.Encode(response); err != nil {
+			return fmt.Errorf("cannot write response: %w", err)
+		}
+	}
+}
@@ -6,10 +6,9 @@ package provider_example
 import (
 	"context"
 	"errors"
-	"log"
+	"time"
 
 	. "github.com/outscale/frieza/internal/common"
-	"github.com/teris-io/cli"
 )
 
 const (
@@ -42,9 +41,22 @@ func Types() []ObjectType {
 	return object_types
 }
 
-func Cli() (string, cli.Command) {
-	return Name, cli.NewCommand(Name, "create new Example profile").
-		WithOption(cli.NewOption("api-key", "Api key"))
+// Dependencies is optional: declare which types must be deleted before others
+// (for example a "MyResourceGroup" type depending on "MyResource").
+func Dependencies() map[ObjectType][]ObjectType {
+	return map[ObjectType][]ObjectType{}
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:        Name,
+		Description: "Example",
+		New:         ProviderConstructor(New),
+		Types:       Types,
+		Config: []ProviderConfigOption{
+			{Name: "api-key", Description: "Api key"},
+		},
+	})
 }
 
 func (provider *ProviderExample) Name() string {
@@ -55,6 +67,10 @@ func (provider *ProviderExample) Types() []ObjectType {
 	return Types()
 }
 
+func (provider *ProviderExample) Dependencies() map[ObjectType][]ObjectType {
+	return Dependencies()
+}
+
 func (provider *ProviderExample) AuthTest(ctx context.Context) error {
 	if provider.apiKey != "123" {
 		return errors.New("cannot authenticate with API Key")
@@ -70,27 +86,40 @@ func (provider *ProviderExample) ReadObjects(ctx context.Context, typeName strin
 	return []Object{}, nil
 }
 
-func (provider *ProviderExample) DeleteObjects(ctx context.Context, typeName string, objects []Object) {
+func (provider *ProviderExample) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
 	switch typeName {
 	case typeMyResource:
-		provider.deleteMyResources(ctx, objects)
+		return provider.deleteMyResources(ctx, objects)
 	}
+	return nil
 }
 
-func (provider *ProviderExample) StringObject(object string, typeName string) string {
-	return object
+func (provider *ProviderExample) StringObject(object Object, typeName string) string {
+	return object.String()
 }
 
 func (provider *ProviderExample) readMyResources(ctx context.Context) ([]Object, error) {
 	MyResources := make([]Object, 0, 2)
-	// Get remote objects
+	// Get remote objects and fill as much metadata as the API provides
 	// ...
-	MyResources = append(MyResources, "MyResource-id-1")
-	MyResources = append(MyResources, "MyResource-id-2")
+	for _, id := range []string{"MyResource-id-1", "MyResource-id-2"} {
+		myResource := NewObject(id)
+		myResource.Name = "my-resource"
+		myResource.Tags = map[string]string{"env": "example"}
+		myResource.SetCreatedAt(time.Now())
+		MyResources = append(MyResources, myResource)
+	}
 	return MyResources, nil
 }
 
-func (provider *ProviderExample) deleteMyResources(ctx context.Context, myResources []Object) {
-	log.Printf("Deleting MyResources: %s ... ", myResources)
-	log.Println("OK")
+func (provider *ProviderExample) deleteMyResources(ctx context.Context, myResources []Object) []DeleteResult {
+	ContextLogger(ctx).Printf("Deleting MyResources: %s ... ", ObjectIds(myResources))
+	ContextLogger(ctx).Println("OK")
+	// Report one result per object, with DeleteFailed and the matching
+	// DeleteErrorKind when the API refuses the deletion.
+	results := make([]DeleteResult, 0, len(myResources))
+	for _, myResource := range myResources {
+		results = append(results, DeleteSucceeded(myResource))
+	}
+	return results
 }
@@ -5,15 +5,20 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"log"
+	"iter"
+	"net/http"
+	"strconv"
 	"strings"
 
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	credentials_v2 "github.com/aws/aws-sdk-go-v2/credentials"
+	s3_v2 "github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	. "github.com/outscale/frieza/internal/common"
-	"github.com/teris-io/cli"
 )
 
 const (
@@ -24,6 +29,7 @@ const (
 
 type S3 struct {
 	client *s3.S3
+	region string
 }
 
 func checkConfig(config ProviderConfig) error {
@@ -42,15 +48,58 @@ func checkConfig(config ProviderConfig) error {
 	return nil
 }
 
-func New(config ProviderConfig, debug bool) (*S3, error) {
+// clientSettings are read once from the provider configuration, so that the
+// provider client (AWS SDK v1) and the snapshot store client (AWS SDK v2)
+// reach buckets the same way.
+type clientSettings struct {
+	// endpoint always has a scheme, https when the configuration has none.
+	endpoint string
+	region   string
+	ak       string
+	sk       string
+	// pathStyle puts bucket names in the URL path instead of the host.
+	pathStyle  bool
+	httpClient *http.Client
+	debug      bool
+}
+
+func newClientSettings(config ProviderConfig, debug bool) (*clientSettings, error) {
 	if err := checkConfig(config); err != nil {
 		return nil, err
 	}
-	endpoint := config["endpoint"]
-	region := config["region"]
+	rate, err := ParseRateLimit(config, 0)
+	if err != nil {
+		return nil, err
+	}
+	settings := &clientSettings{
+		endpoint: config["endpoint"],
+		region:   config["region"],
+		ak:       config["ak"],
+		sk:       config["sk"],
+		debug:    debug,
+	}
+	if !strings.Contains(settings.endpoint, "://") {
+		settings.endpoint = "https://" + settings.endpoint
+	}
+	if rate > 0 {
+		settings.httpClient = NewRateLimitedClient(rate)
+	}
+	return settings, nil
+}
+
+func New(config ProviderConfig, debug bool) (*S3, error) {
+	settings, err := newClientSettings(config, debug)
+	if err != nil {
+		return nil, err
+	}
 	sessionConfig := aws.Config{
-		Endpoint: &endpoint,
-		Region:   &region,
+		Endpoint:         &settings.endpoint,
+		Region:           &settings.region,
+		S3ForcePathStyle: &settings.pathStyle,
+		Credentials:      credentials.NewStaticCredentials(settings.ak, settings.sk, ""),
+	}
+	if settings.httpClient != nil {
+		sessionConfig.HTTPClient = settings.httpClient
 	}
 	if debug {
 		sessionConfig.LogLevel = aws.LogLevel(aws.LogDebugWithRequestErrors |
@@ -63,15 +112,36 @@ func New(config ProviderConfig, debug bool) (*S3, error) {
 		return nil, errors.New("cannot create s3 session")
 	}
 
-	creds := credentials.NewStaticCredentials(config["ak"], config["sk"], "")
-	awsConfig := aws.NewConfig().WithCredentials(creds)
-	client := s3.New(session, awsConfig)
-
 	return &S3{
-		client: client,
+		client: s3.New(session),
+		region: settings.region,
 	}, nil
 }
 
+// NewS3Client builds a client of the AWS SDK v2 from a provider
+// configuration, to store data like snapshots. It uses the settings of the
+// provider client and nothing from the environment.
+func NewS3Client(ctx context.Context, config ProviderConfig, debug bool) (*s3_v2.Client, error) {
+	settings, err := newClientSettings(config, debug)
+	if err != nil {
+		return nil, err
+	}
+	options := s3_v2.Options{
+		Region:       settings.region,
+		Credentials:  credentials_v2.NewStaticCredentialsProvider(settings.ak, settings.sk, ""),
+		BaseEndpoint: &settings.endpoint,
+		UsePathStyle: settings.pathStyle,
+		AppID:        "frieza/" + FullVersion(),
+	}
+	if settings.httpClient != nil {
+		options.HTTPClient = settings.httpClient
+	}
+	if settings.debug {
+		options.ClientLogMode = aws_v2.LogRequest | aws_v2.LogResponseWithBody
+	}
+	return s3_v2.New(options), nil
+}
+
 func Types() []ObjectType {
 	object_types := []ObjectType{
 		typeBucketObject,
@@ -80,12 +150,32 @@ func Types() []ObjectType {
 	return object_types
 }
 
-func Cli() (string, cli.Command) {
-	return Name, cli.NewCommand(Name, "create new S3 profile").
-		WithOption(cli.NewOption("endpoint", "S3 endpoint")).
-		WithOption(cli.NewOption("region", "region's name")).
-		WithOption(cli.NewOption("ak", "access key")).
-		WithOption(cli.NewOption("sk", "secret key"))
+func Dependencies() map[ObjectType][]ObjectType {
+	return map[ObjectType][]ObjectType{
+		typeBucket: {typeBucketObject},
+	}
+}
+
+// TaggedTypes lists types selectable by tags: bucket tags also apply to their
+// objects.
+func TaggedTypes() []ObjectType {
+	return []ObjectType{typeBucketObject, typeBucket}
+}
+
+func init() {
+	RegisterProvider(ProviderRegistration{
+		Name:        Name,
+		Description: "S3",
+		New:         ProviderConstructor(New),
+		Types:       Types,
+		Config: []ProviderConfigOption{
+			{Name: "endpoint", Description: "S3 endpoint"},
+			{Name: "region", Description: "region's name"},
+			{Name: "ak", Description: "access key"},
+			{Name: "sk", Description: "secret key"},
+			RateLimitOption(0),
+		},
+	})
 }
 
 func (provider *S3) Name() string {
@@ -96,6 +186,14 @@ func (provider *S3) Types() []ObjectType {
 	return Types()
 }
 
+func (provider *S3) Dependencies() map[ObjectType][]ObjectType {
+	return Dependencies()
+}
+
+func (provider *S3) TaggedTypes() []ObjectType {
+	return TaggedTypes()
+}
+
 func (provider *S3) AuthTest(ctx context.Context) error {
 	_, err := provider.client.ListBucketsWithContext(ctx, nil)
 	if err != nil {
@@ -114,23 +212,69 @@ func (provider *S3) ReadObjects(ctx context.Context, typeName string) ([]Object,
 	return []Object{}, nil
 }
 
-func (provider *S3) DeleteObjects(ctx context.Context, typeName string, objects []Object) {
+func (provider *S3) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
 	switch typeName {
 	case typeBucketObject:
-		provider.deleteBucketObjects(ctx, objects)
+		return provider.deleteBucketObjects(ctx, objects)
 	case typeBucket:
-		provider.deleteBuckets(ctx, objects)
+		return provider.deleteBuckets(ctx, objects)
+	}
+	return nil
+}
+
+// ExistingObjects checks objects with HEAD requests instead of listing every
+// bucket.
+func (provider *S3) ExistingObjects(ctx context.Context, typeName string, objects []Object) ([]Object, error) {
+	existing := make([]Object, 0, len(objects))
+	for _, object := range objects {
+		var err error
+		switch typeName {
+		case typeBucketObject:
+			bucketName, key, decodeErr := decodeBucketobject(&object.Id)
+			if decodeErr != nil {
+				// It cannot have been deleted, keep it.
+				existing = append(existing, object)
+				continue
+			}
+			_, err = provider.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+				Bucket: &bucketName,
+				Key:    &key,
+			})
+		case typeBucket:
+			bucketName, decodeErr := decodeBucket(&object.Id)
+			if decodeErr != nil {
+				// It cannot have been deleted, keep it.
+				existing = append(existing, object)
+				continue
+			}
+			_, err = provider.client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+				Bucket: &bucketName,
+			})
+		default:
+			return nil, errors.ErrUnsupported
+		}
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("head %s: %w", provider.StringObject(object, typeName), err)
+		}
+		existing = append(existing, object)
 	}
+	return existing, nil
 }
 
-func (provider *S3) StringObject(object string, typeName string) string {
+func (provider *S3) StringObject(object Object, typeName string) string {
+	if len(object.Name) > 0 {
+		return object.Name
+	}
 	switch typeName {
 	case typeBucketObject:
-		if bucketName, key, err := decodeBucketobject(&object); err == nil {
+		if bucketName, key, err := decodeBucketobject(&object.Id); err == nil {
 			return bucketName + ":" + key
 		}
 	case typeBucket:
-		if bucketName, err := decodeBucket(&object); err == nil {
+		if bucketName, err := decodeBucket(&object.Id); err == nil {
 			return bucketName
 		}
 	}
@@ -164,46 +308,115 @@ func decodeBucketobject(encodedObject *string) (string, string, error) {
 	return bucketName, key, nil
 }
 
-func (provider *S3) readBucketObjects(ctx context.Context) ([]Object, error) {
-	objects := make([]Object, 0)
-	result, err := provider.client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
-	if err != nil {
-		return nil, err
+// StreamObjects lists bucket objects page by page, buckets can hold millions
+// of them.
+func (provider *S3) StreamObjects(ctx context.Context, typeName string) iter.Seq2[Object, error] {
+	if typeName != typeBucketObject {
+		return ObjectsSeq(provider.ReadObjects(ctx, typeName))
 	}
-	for _, bucket := range result.Buckets {
-		result, err := provider.client.ListObjectsWithContext(ctx, &s3.ListObjectsInput{
-			Bucket: bucket.Name,
-		})
+	return provider.streamBucketObjects(ctx)
+}
+
+func (provider *S3) readBucketObjects(ctx context.Context) ([]Object, error) {
+	return CollectObjects(provider.streamBucketObjects(ctx))
+}
+
+func (provider *S3) streamBucketObjects(ctx context.Context) iter.Seq2[Object, error] {
+	return func(yield func(Object, error) bool) {
+		result, err := provider.client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
 		if err != nil {
-			continue
+			yield(Object{}, err)
+			return
 		}
-		for _, object := range result.Contents {
-			objects = append(objects, encodeBucketObject(bucket.Name, object.Key))
+		for _, bucket := range result.Buckets {
+			var bucketTags map[string]string
+			tagsRead := false
+			stopped := false
+			firstPage := true
+			var tagsErr error
+			err := provider.client.ListObjectsPagesWithContext(ctx, &s3.ListObjectsInput{
+				Bucket: bucket.Name,
+			}, func(page *s3.ListObjectsOutput, lastPage bool) bool {
+				firstPage = false
+				if !tagsRead && len(page.Contents) > 0 {
+					bucketTags, tagsErr = provider.readBucketTags(ctx, bucket.Name)
+					if tagsErr != nil {
+						return false
+					}
+					tagsRead = true
+				}
+				for _, object := range page.Contents {
+					if !yield(provider.newBucketObject(bucket.Name, object, bucketTags), nil) {
+						stopped = true
+						return false
+					}
+				}
+				return true
+			})
+			if firstPage && isUnlistable(err) {
+				continue
+			}
+			if tagsErr != nil {
+				yield(Object{}, tagsErr)
+				return
+			}
+			// A partial listing would make clean delete the objects of the
+			// missing pages.
+			if err != nil {
+				yield(Object{}, fmt.Errorf("list objects of bucket %s: %w", *bucket.Name, err))
+				return
+			}
+			if stopped {
+				return
+			}
 		}
 	}
-	return objects, nil
 }
 
-func (provider *S3) deleteBucketObjects(ctx context.Context, bucketObjects []Object) {
-	for _, encodedBucketObject := range bucketObjects {
-		log.Printf(
+func (provider *S3) newBucketObject(bucketName *string, object *s3.Object, bucketTags map[string]string) Object {
+	bucketObject := NewObject(encodeBucketObject(bucketName, object.Key))
+	bucketObject.Name = *bucketName + ":" + *object.Key
+	bucketObject.Tags = bucketTags
+	bucketObject.Region = provider.region
+	if object.LastModified != nil {
+		bucketObject.SetCreatedAt(*object.LastModified)
+	}
+	bucketObject.SetAttribute("bucket", *bucketName)
+	bucketObject.SetAttribute("key", *object.Key)
+	if object.Size != nil {
+		bucketObject.SetAttribute("size", strconv.FormatInt(*object.Size, 10))
+	}
+	if object.ETag != nil {
+		bucketObject.SetAttribute("etag", strings.Trim(*object.ETag, "\""))
+	}
+	return bucketObject
+}
+
+func (provider *S3) deleteBucketObjects(ctx context.Context, bucketObjects []Object) []DeleteResult {
+	results := make([]DeleteResult, 0, len(bucketObjects))
+	for _, bucketObject := range bucketObjects {
+		ContextLogger(ctx).Printf(
 			"Deleting object: %s ... ",
-			provider.StringObject(encodedBucketObject, typeBucketObject),
+			provider.StringObject(bucketObject, typeBucketObject),
 		)
-		bucketName, key, err := decodeBucketobject(&encodedBucketObject)
+		bucketName, key, err := decodeBucketobject(&bucketObject.Id)
 		if err != nil {
-			log.Println("Error while reading object details: ", err.Error())
+			ContextLogger(ctx).Println("Error while reading object details: ", err.Error())
+			results = append(results, DeleteFailed(bucketObject, DeleteErrorForbidden, err))
+			continue
 		}
 		_, err = provider.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
 			Bucket: &bucketName,
 			Key:    &key,
 		})
 		if err != nil {
-			log.Println("Error while deleting object: ", err.Error())
+			ContextLogger(ctx).Println("Error while deleting object: ", err.Error())
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(bucketObject, err))
 	}
+	return results
 }
 
 func encodeBucket(bucketName *string) string {
@@ -226,25 +439,109 @@ func (provider *S3) readBuckets(ctx context.Context) ([]Object, error) {
 		return nil, fmt.Errorf("read buckets: %w", err)
 	}
 	for _, b := range result.Buckets {
-		buckets = append(buckets, encodeBucket(b.Name))
+		bucket := NewObject(encodeBucket(b.Name))
+		bucket.Name = *b.Name
+		bucket.Tags, err = provider.readBucketTags(ctx, b.Name)
+		if err != nil {
+			return nil, err
+		}
+		bucket.Region = provider.region
+		if b.CreationDate != nil {
+			bucket.SetCreatedAt(*b.CreationDate)
+		}
+		buckets = append(buckets, bucket)
 	}
 	return buckets, nil
 }
 
-func (provider *S3) deleteBuckets(ctx context.Context, buckets []Object) {
-	for _, b64Bucket := range buckets {
-		BucketName, err := decodeBucket(&b64Bucket)
+// readBucketTags returns nil when the bucket has no tags or they are not
+// wanted. Other errors are returned: ignoring them would let objects escape
+// tag filters.
+func (provider *S3) readBucketTags(ctx context.Context, bucketName *string) (map[string]string, error) {
+	if !TagsWanted(ctx) {
+		return nil, nil
+	}
+	result, err := provider.client.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{
+		Bucket: bucketName,
+	})
+	if awsErrorCode(err) == "NoSuchTagSet" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read tags of bucket %s: %w", aws.StringValue(bucketName), err)
+	}
+	if len(result.TagSet) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(result.TagSet))
+	for _, tag := range result.TagSet {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return tags, nil
+}
+
+func (provider *S3) deleteBuckets(ctx context.Context, buckets []Object) []DeleteResult {
+	results := make([]DeleteResult, 0, len(buckets))
+	for _, bucket := range buckets {
+		BucketName, err := decodeBucket(&bucket.Id)
 		if err != nil {
+			results = append(results, DeleteFailed(bucket, DeleteErrorForbidden, err))
 			continue
 		}
-		log.Printf("Deleting bucket: %s ... ", BucketName)
+		ContextLogger(ctx).Printf("Deleting bucket: %s ... ", BucketName)
 		_, err = provider.client.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{
 			Bucket: &BucketName,
 		})
 		if err != nil {
-			log.Println("Error while deleting bucket: ", err.Error())
+			ContextLogger(ctx).Println("Error while deleting bucket: ", err.Error())
 		} else {
-			log.Println("OK")
+			ContextLogger(ctx).Println("OK")
 		}
+		results = append(results, newDeleteResult(bucket, err))
+	}
+	return results
+}
+
+func newDeleteResult(object Object, err error) DeleteResult {
+	if err == nil {
+		return DeleteSucceeded(object)
+	}
+	kind := DeleteErrorRetryable
+	switch awsErrorCode(err) {
+	case "NoSuchBucket", "NoSuchKey", "NotFound":
+		kind = DeleteErrorNotFound
+	case "BucketNotEmpty":
+		kind = DeleteErrorDependency
+	case "AccessDenied", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		kind = DeleteErrorForbidden
+	case "SlowDown", "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException":
+		kind = DeleteErrorThrottled
 	}
+	return DeleteFailed(object, kind, err)
+}
+
+func isNotFound(err error) bool {
+	switch awsErrorCode(err) {
+	case "NoSuchBucket", "NoSuchKey", "NotFound":
+		return true
+	}
+	return false
+}
+
+// isUnlistable reports whether the objects of a bucket cannot be listed at
+// all, such buckets being skipped.
+func isUnlistable(err error) bool {
+	switch awsErrorCode(err) {
+	case "AccessDenied", "NoSuchBucket":
+		return true
+	}
+	return false
+}
+
+func awsErrorCode(err error) string {
+	var awsError awserr.Error
+	if errors.As(err, &awsError) {
+		return awsError.Code()
+	}
+	return ""
 }
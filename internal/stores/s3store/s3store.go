@@ -0,0 +1,165 @@
+// Package s3store keeps snapshots in a S3 compatible bucket, so they can be
+// shared between machines.
+package s3store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	. "github.com/outscale/frieza/internal/common"
+	oos "github.com/outscale/frieza/internal/providers/outscale_oos"
+	s3provider "github.com/outscale/frieza/internal/providers/s3"
+)
+
+const Scheme = "s3"
+
+// Store keeps each snapshot as a JSON object of a bucket, under an optional
+// prefix.
+type Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func init() {
+	RegisterSnapshotStore(Scheme, Open)
+}
+
+// Open builds a store from a location like "s3://bucket/prefix?profile=name",
+// the client being configured like the s3 or outscale_oos provider of the
+// profile.
+func Open(location *url.URL, config *Config) (SnapshotStore, error) {
+	if len(location.Host) == 0 {
+		return nil, errors.New("snapshot store needs a bucket, like s3://bucket/prefix?profile=name")
+	}
+	profileName := location.Query().Get("profile")
+	if len(profileName) == 0 {
+		return nil, errors.New("snapshot store needs a profile, like s3://bucket/prefix?profile=name")
+	}
+	profile, err := config.GetProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClient(context.Background(), profile)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.Trim(location.Path, "/")
+	if len(prefix) > 0 {
+		prefix += "/"
+	}
+	return &Store{client: client, bucket: location.Host, prefix: prefix}, nil
+}
+
+func newClient(ctx context.Context, profile *Profile) (*s3.Client, error) {
+	providers, err := profile.GetProviders()
+	if err != nil {
+		return nil, err
+	}
+	for _, providerName := range providers {
+		switch providerName {
+		case oos.Name:
+			return oos.NewS3Client(ctx, profile.Config, false)
+		case s3provider.Name:
+			return s3provider.NewS3Client(ctx, profile.Config, false)
+		}
+	}
+	return nil, fmt.Errorf("profile %s has no %s or %s provider", profile.Name, oos.Name, s3provider.Name)
+}
+
+func (store *Store) key(name string) string {
+	return store.prefix + name + ".json"
+}
+
+// Open streams the snapshot, compact snapshots of large inventories being
+// read without holding them in memory.
+func (store *Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	key := store.key(name)
+	output, err := store.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &store.bucket,
+		Key:    &key,
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, fmt.Errorf("snapshot %s: %w", name, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (store *Store) Read(ctx context.Context, name string) ([]byte, error) {
+	body, err := store.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (store *Store) Write(ctx context.Context, name string, data []byte) error {
+	key := store.key(name)
+	contentType := "application/json"
+	_, err := store.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &store.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: &contentType,
+	})
+	return err
+}
+
+func (store *Store) Delete(ctx context.Context, name string) error {
+	key := store.key(name)
+	// Deleting a missing key succeeds on S3, check it exists first.
+	_, err := store.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: &store.bucket,
+		Key:    &key,
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return fmt.Errorf("snapshot %s: %w", name, fs.ErrNotExist)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = store.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &store.bucket,
+		Key:    &key,
+	})
+	return err
+}
+
+func (store *Store) List(ctx context.Context) ([]string, error) {
+	var names []string
+	paginator := s3.NewListObjectsV2Paginator(store.client, &s3.ListObjectsV2Input{
+		Bucket: &store.bucket,
+		Prefix: &store.prefix,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(*object.Key, store.prefix)
+			if path.Dir(name) != "." || path.Ext(name) != ".json" {
+				continue
+			}
+			names = append(names, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
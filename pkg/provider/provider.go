@@ -0,0 +1,97 @@
+// Package provider is the API of frieza providers for other modules, which
+// cannot import frieza internal packages. A provider package calls
+// RegisterProvider from its init function, a frieza build then adds it with a
+// blank import in cmd/frieza/providers.go.
+// Check docs/CONTRIBUTING.md for more details.
+package provider
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/outscale/frieza/internal/common"
+)
+
+type (
+	ObjectType           = common.ObjectType
+	ProviderConfig       = common.ProviderConfig
+	ProviderConfigOption = common.ProviderConfigOption
+	ProviderRegistration = common.ProviderRegistration
+	Provider             = common.Provider
+	DependencyProvider   = common.DependencyProvider
+	TagProvider          = common.TagProvider
+	ExistenceChecker     = common.ExistenceChecker
+	ObjectStreamer       = common.ObjectStreamer
+	ConcurrencyLimiter   = common.ConcurrencyLimiter
+	Object               = common.Object
+	Objects              = common.Objects
+	DeleteResult         = common.DeleteResult
+	DeleteErrorKind      = common.DeleteErrorKind
+	ProtectedError       = common.ProtectedError
+)
+
+const (
+	DeleteErrorRetryable  = common.DeleteErrorRetryable
+	DeleteErrorDependency = common.DeleteErrorDependency
+	DeleteErrorForbidden  = common.DeleteErrorForbidden
+	DeleteErrorNotFound   = common.DeleteErrorNotFound
+	DeleteErrorThrottled  = common.DeleteErrorThrottled
+)
+
+// RegisterProvider makes a provider available to profiles, it panics if the
+// name is already registered.
+func RegisterProvider(registration ProviderRegistration) {
+	common.RegisterProvider(registration)
+}
+
+// ProviderConstructor adapts the New function of a provider package to
+// ProviderRegistration.New.
+func ProviderConstructor[T Provider](newProvider func(config ProviderConfig, debug bool) (T, error)) func(ProviderConfig, bool) (Provider, error) {
+	return common.ProviderConstructor(newProvider)
+}
+
+func NewObject(id string) Object {
+	return common.NewObject(id)
+}
+
+func ObjectIds(objects []Object) []string {
+	return common.ObjectIds(objects)
+}
+
+func DeleteSucceeded(object Object) DeleteResult {
+	return common.DeleteSucceeded(object)
+}
+
+func DeleteFailed(object Object, kind DeleteErrorKind, err error) DeleteResult {
+	return common.DeleteFailed(object, kind, err)
+}
+
+// ContextLogger returns the logger provider messages are written to.
+func ContextLogger(ctx context.Context) *log.Logger {
+	return common.ContextLogger(ctx)
+}
+
+// TagsWanted tells whether tags costing extra API calls must be read.
+func TagsWanted(ctx context.Context) bool {
+	return common.TagsWanted(ctx)
+}
+
+// CheckProtected returns a *ProtectedError when one of objects, like an
+// object linked to the one being deleted, matches a protect rule.
+func CheckProtected(ctx context.Context, typeName ObjectType, objects []Object) error {
+	return common.CheckProtected(ctx, typeName, objects)
+}
+
+// RateLimitOption is the profile option limiting the API calls of a provider.
+func RateLimitOption(defaultRate float64) ProviderConfigOption {
+	return common.RateLimitOption(defaultRate)
+}
+
+func ParseRateLimit(config ProviderConfig, defaultRate float64) (float64, error) {
+	return common.ParseRateLimit(config, defaultRate)
+}
+
+func NewRateLimitedClient(rate float64) *http.Client {
+	return common.NewRateLimitedClient(rate)
+}
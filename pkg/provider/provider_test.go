@@ -0,0 +1,58 @@
+package provider_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/outscale/frieza/internal/common"
+	. "github.com/outscale/frieza/pkg/provider"
+)
+
+// externalProvider only uses this package, like a provider of another module.
+type externalProvider struct{}
+
+func newExternalProvider(config ProviderConfig, debug bool) (*externalProvider, error) {
+	return &externalProvider{}, nil
+}
+
+func (provider *externalProvider) Name() string                       { return "external_test" }
+func (provider *externalProvider) Types() []ObjectType                { return []ObjectType{"widget"} }
+func (provider *externalProvider) AuthTest(ctx context.Context) error { return nil }
+func (provider *externalProvider) StringObject(object Object, typeName string) string {
+	return object.String()
+}
+
+func (provider *externalProvider) ReadObjects(ctx context.Context, typeName string) ([]Object, error) {
+	return []Object{NewObject("widget-1")}, nil
+}
+
+func (provider *externalProvider) DeleteObjects(ctx context.Context, typeName string, objects []Object) []DeleteResult {
+	results := make([]DeleteResult, 0, len(objects))
+	for _, object := range objects {
+		results = append(results, DeleteSucceeded(object))
+	}
+	return results
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider(ProviderRegistration{
+		Name:        "external_test",
+		Description: "External",
+		New:         ProviderConstructor(newExternalProvider),
+		Types:       func() []ObjectType { return []ObjectType{"widget"} },
+	})
+	provider, err := common.NewProvider("external_test", ProviderConfig{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := common.DeleteObjects(context.Background(), &provider, common.Objects{"widget": {NewObject("widget-1")}})
+	if len(results["widget"]) != 1 || !results["widget"][0].Deleted() {
+		t.Errorf("registered provider did not delete: %v", results)
+	}
+	if !slices.ContainsFunc(common.RegisteredProviders(), func(registration ProviderRegistration) bool {
+		return registration.Name == "external_test"
+	}) {
+		t.Error("provider not listed")
+	}
+}